@@ -1,96 +1,275 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+	"github.com/jalikey/zysj-backend/internal/auth"
+	"github.com/jalikey/zysj-backend/internal/cache"
+	"github.com/jalikey/zysj-backend/internal/config"
 	"github.com/jalikey/zysj-backend/internal/database" // !! 修改为你的模块路径
-	"github.com/jalikey/zysj-backend/internal/handlers"   // !! 新增导入
+	"github.com/jalikey/zysj-backend/internal/handlers" // !! 新增导入
+	"github.com/jalikey/zysj-backend/internal/logging"
+	"github.com/jalikey/zysj-backend/internal/metrics"
+	"github.com/jalikey/zysj-backend/internal/migrate"
+	"github.com/jalikey/zysj-backend/internal/ratelimit"
+	"github.com/jalikey/zysj-backend/internal/repository"
+	"github.com/jalikey/zysj-backend/internal/tracing"
 )
 
 func main() {
-	// 1. Load environment variables
-	err := godotenv.Load()
+	configFile := flag.String("config", "", "path to a YAML or TOML config file (default $CONFIG_FILE)")
+	migrateOnStart := flag.Bool("migrate", false, "apply pending database migrations before starting the server")
+	flag.Parse()
+
+	// 1. Load and validate configuration, logging any problem in the
+	// default format until the configured one is known
+	logging.Setup(config.Default().Log, os.Stdout)
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		fatal("Failed to load configuration", err)
+	}
+	logging.Setup(cfg.Log, os.Stdout)
+	auth.Configure(cfg.Auth)
+	shutdownTracing, err := tracing.Setup(context.Background(), "zysj-backend")
 	if err != nil {
-		log.Println("Warning: .env file not found, using environment variables from OS")
+		fatal("Failed to set up tracing", err)
 	}
 
 	// 2. Connect to the database
-	database.ConnectDB()
+	database.ConnectDB(cfg.Database)
 	defer database.CloseDB()
 
-	// 3. Initialize Gin router
-	router := gin.Default()
+	// "server migrate ..." manages the schema and exits without serving
+	if flag.Arg(0) == "migrate" {
+		if err := migrate.RunCommand(context.Background(), database.DB, flag.Args()[1:], os.Stdout); err != nil {
+			fatal("Migration failed", err)
+		}
+		return
+	}
+
+	if *migrateOnStart || cfg.Database.AutoMigrate {
+		n, err := migrate.Up(context.Background(), database.DB)
+		if err != nil {
+			fatal("Failed to apply migrations", err)
+		}
+		slog.Info("Applied pending migrations", "count", n)
+	}
+
+	// 3. Initialize Gin router and handlers
+	router := gin.New()
+	// Only believe forwarded client IPs from our own load balancers
+	if err := router.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		fatal("Invalid trusted proxies", err)
+	}
+	router.Use(
+		otelgin.Middleware("zysj-backend"),
+		handlers.RequestID(),
+		handlers.RequestLogger(),
+		metrics.Middleware(),
+		handlers.Recovery(),
+		handlers.SecurityHeaders(cfg.HTTP.Security),
+		handlers.CORS(cfg.HTTP.CORS),
+		handlers.ErrorHandler(),
+	)
+	router.NoRoute(handlers.NotFound)
+	if err := metrics.RegisterPool(database.DB); err != nil {
+		slog.Error("Failed to register database pool metrics", "error", err)
+	}
+	store := repository.NewPostgresStore(database.DB)
+	var articles repository.ArticleStore = store
+	var categories repository.CategoryStore = store
+	// Background workers run until shutdown, which waits for them before
+	// closing the database pool
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	defer stopWorkers()
+	if cfg.ReadCache.Size > 0 {
+		cached := cache.New(cache.NewLRU(cfg.ReadCache.Size), time.Duration(cfg.ReadCache.TTL), store, store)
+		articles, categories = cached, cached
+		// Other instances announce their writes through Postgres
+		workers.Go(func() { cached.Listen(workersCtx, database.DB) })
+	}
+	h := handlers.New(articles, categories, store, store)
+	queryTimeout := handlers.Timeout(time.Duration(cfg.HTTP.Timeouts.Default))
+	// Full-text ranking is the most expensive query, so it gets its own budget
+	searchTimeout := handlers.Timeout(time.Duration(cfg.HTTP.Timeouts.Search))
+	adminTimeout := handlers.Timeout(time.Duration(cfg.HTTP.Timeouts.Admin))
+	// Public reads may be cached by browsers and CDNs
+	publicCache := handlers.PublicCache(cfg.HTTP.Cache)
+
+	// Each client is throttled separately in every route group
+	var limiter ratelimit.Limiter = ratelimit.NewMemory()
+	if cfg.HTTP.RateLimit.Backend == "postgres" {
+		shared := ratelimit.NewPostgres(database.DB)
+		limiter = shared
+		workers.Go(func() { shared.Sweep(workersCtx, time.Minute) })
+	}
+	rateLimit := func(group string, rule config.RateLimitRule) gin.HandlerFunc {
+		return handlers.RateLimit(group, limiter, ratelimit.Limit{Rate: rule.Rate.PerSecond(), Burst: rule.Burst})
+	}
+	publicLimit := rateLimit("public", cfg.HTTP.RateLimit.Public)
+	// Full-text search is throttled harder than other reads
+	searchLimit := rateLimit("search", cfg.HTTP.RateLimit.Search)
+	loginLimit := rateLimit("login", cfg.HTTP.RateLimit.Login)
+	adminLimit := rateLimit("admin", cfg.HTTP.RateLimit.Admin)
 
 	// 4. Setup routes
+	// Probes: liveness never touches the database, readiness checks it.
+	// Metrics and the details of failed checks are only on the internal port.
+	router.GET("/healthz", handlers.Healthz)
+	router.GET("/readyz", handlers.Readyz(database.DB, false))
 	// Simple health check route
 	router.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"message": "pong",
 		})
 	})
-	
+
 	// Public API routes
 	apiV1 := router.Group("/api/v1")
 	{
-		apiV1.POST("/login", handlers.Login)
+		apiV1.POST("/login", loginLimit, queryTimeout, h.Login)
 
-		apiV1.GET("/search", handlers.SearchArticles)
-		apiV1.GET("/categories", handlers.GetCategories)
-		apiV1.GET("/categories/:slug", handlers.GetArticlesByCategory)
+		apiV1.GET("/search", searchLimit, searchTimeout, publicCache, h.SearchArticles)
+		apiV1.GET("/categories", publicLimit, queryTimeout, publicCache, h.GetCategories)
+		apiV1.GET("/categories/:slug", publicLimit, queryTimeout, publicCache, h.GetArticlesByCategory)
 		// We keep the public GET routes for articles for simplicity
-		apiV1.GET("/articles", handlers.GetArticles)
-		apiV1.GET("/articles/:id", handlers.GetArticleByID)
+		apiV1.GET("/articles", publicLimit, queryTimeout, publicCache, h.GetArticles)
+		apiV1.GET("/articles/:id", publicLimit, queryTimeout, publicCache, h.GetArticleByID)
+		apiV1.GET("/articles/by-slug/:slug", publicLimit, queryTimeout, publicCache, h.GetArticleBySlug)
+		apiV1.GET("/articles/:id/sections/:anchor", publicLimit, queryTimeout, publicCache, h.GetArticleSection)
 	}
 
 	// Admin API routes
 	adminV1 := router.Group("/api/v1/admin")
-	adminV1.Use(handlers.AuthMiddleware())
+	// Throttle before authenticating, so that guessing keys and tokens can't
+	// hammer the database; admin clients are therefore limited by IP
+	adminV1.Use(adminLimit, handlers.AuthMiddleware(store), adminTimeout)
+	// API keys only reach the endpoints their scopes allow
+	readScope := handlers.RequireScope(auth.ScopeRead)
+	articlesWrite := handlers.RequireScope(auth.ScopeArticlesWrite)
+	categoriesWrite := handlers.RequireScope(auth.ScopeCategoriesWrite)
 	{
 		// Dashboard test route
-		adminV1.GET("/dashboard", func(c *gin.Context) {
+		adminV1.GET("/dashboard", readScope, func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"message": "Welcome to the admin dashboard!"})
 		})
 
 		// Articles CRUD
 		// GET is already public, but we can add it here too if we want admin-specific logic later
-		adminV1.GET("/articles", handlers.GetArticles) 
-		adminV1.POST("/articles", handlers.CreateArticle)
-		adminV1.PUT("/articles/:id", handlers.UpdateArticle)
-		adminV1.DELETE("/articles/:id", handlers.DeleteArticle)
+		adminV1.GET("/articles", readScope, h.GetArticles)
+		adminV1.POST("/articles", articlesWrite, h.CreateArticle)
+		adminV1.PUT("/articles/:id", articlesWrite, h.UpdateArticle)
+		adminV1.PATCH("/articles/:id", articlesWrite, h.PatchArticle)
+		adminV1.DELETE("/articles/:id", articlesWrite, h.DeleteArticle)
 
-	// Categories CRUD
-		adminV1.GET("/categories", handlers.GetCategories) 
-		adminV1.GET("/categories/:id", handlers.GetCategoryByID) // 新增路由
-		adminV1.POST("/categories", handlers.CreateCategory)
-		adminV1.PUT("/categories/:id", handlers.UpdateCategory)
-		adminV1.DELETE("/categories/:id", handlers.DeleteCategory)
+		// Categories CRUD
+		adminV1.GET("/categories", readScope, h.GetCategories)
+		adminV1.GET("/categories/:id", readScope, h.GetCategoryByID) // 新增路由
+		adminV1.POST("/categories", categoriesWrite, h.CreateCategory)
+		adminV1.PUT("/categories/:id", categoriesWrite, h.UpdateCategory)
+		adminV1.PATCH("/categories/:id", categoriesWrite, h.PatchCategory)
+		adminV1.DELETE("/categories/:id", categoriesWrite, h.DeleteCategory)
+
+		// API keys are managed by logged in users only
+		userOnly := handlers.RequireUser()
+		adminV1.GET("/api-keys", userOnly, h.GetAPIKeys)
+		adminV1.POST("/api-keys", userOnly, h.CreateAPIKey)
+		adminV1.DELETE("/api-keys/:id", userOnly, h.RevokeAPIKey)
 	}
 
-	// Admin API routes
-	adminV1 := router.Group("/api/v1/admin")
-	adminV1.Use(handlers.AuthMiddleware()) // Apply auth middleware to this group
-	{
-		// Test route to check if middleware works
-		adminV1.GET("/dashboard", func(c *gin.Context) {
-			c.JSON(http.StatusOK, gin.H{"message": "Welcome to the admin dashboard!"})
-		})
+	// 5. Start the server and wait for SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-		// TODO: Add CRUD routes for articles and categories here in the next step
+	srv := &http.Server{
+		Addr:              ":" + cfg.HTTP.Port,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
 	}
+	serveErr := make(chan error, 2)
+	go func() {
+		slog.Info("Server starting", "port", cfg.HTTP.Port)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	// The internal port is for scrapers and orchestrators on the private
+	// network, and must not be exposed publicly
+	var internalSrv *http.Server
+	if cfg.HTTP.InternalPort != "" {
+		internal := gin.New()
+		internal.Use(handlers.Recovery(), handlers.ErrorHandler())
+		internal.NoRoute(handlers.NotFound)
+		internal.GET("/healthz", handlers.Healthz)
+		internal.GET("/readyz", handlers.Readyz(database.DB, true))
+		internal.GET("/metrics", metrics.Handler())
 
+		internalSrv = &http.Server{
+			Addr:              ":" + cfg.HTTP.InternalPort,
+			Handler:           internal,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			slog.Info("Internal server starting", "port", cfg.HTTP.InternalPort)
+			serveErr <- internalSrv.ListenAndServe()
+		}()
+	}
 
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			stopWorkers()
+			workers.Wait()
+			fatal("Failed to start server", err)
+		}
+	case <-ctx.Done():
+	}
+	// A second signal kills the process straight away
+	stop()
 
-	// 5. Start the server
-	port := os.Getenv("API_PORT")
-	if port == "" {
-		port = "8080" // Default port
+	// 6. Drain in-flight requests, stop the background workers, then release
+	// the database pool. Once the timeout passes the remaining connections
+	// are closed forcibly.
+	slog.Info("Shutting down, draining in-flight requests")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.HTTP.ShutdownTimeout))
+	defer cancel()
+	drain(shutdownCtx, srv)
+	if internalSrv != nil {
+		internalSrv.Close()
+	}
+	stopWorkers()
+	workers.Wait()
+	database.CloseDB()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
 	}
+	slog.Info("Server stopped")
+}
+
+// fatal logs err, releases the database pool and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	database.CloseDB()
+	os.Exit(1)
+}
 
-	log.Printf("Server starting on port %s\n", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+// drain stops srv accepting connections and waits for its in-flight
+// requests until ctx is done, then closes the connections still open.
+func drain(ctx context.Context, srv *http.Server) {
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Error("Graceful shutdown did not complete", "error", err)
+		srv.Close()
 	}
-}
\ No newline at end of file
+}
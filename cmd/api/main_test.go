@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	tests := []struct {
+		name    string
+		work    time.Duration
+		timeout time.Duration
+		wantOK  bool
+	}{
+		{"request finishes in time", 50 * time.Millisecond, 5 * time.Second, true},
+		{"request outlives the timeout", 5 * time.Second, 50 * time.Millisecond, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				select {
+				case <-time.After(tt.work):
+					w.WriteHeader(http.StatusNoContent)
+				case <-r.Context().Done():
+				}
+			})}
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			go srv.Serve(ln)
+
+			result := make(chan error, 1)
+			go func() {
+				resp, err := http.Get("http://" + ln.Addr().String())
+				if err == nil {
+					resp.Body.Close()
+				}
+				result <- err
+			}()
+			<-started
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			begin := time.Now()
+			drain(ctx, srv)
+			if elapsed := time.Since(begin); elapsed > time.Second {
+				t.Errorf("drain took %v", elapsed)
+			}
+
+			if err := <-result; (err == nil) != tt.wantOK {
+				t.Errorf("in-flight request error = %v, want success %v", err, tt.wantOK)
+			}
+			if _, err := http.Get("http://" + ln.Addr().String()); err == nil {
+				t.Error("server still accepts connections after draining")
+			}
+		})
+	}
+}
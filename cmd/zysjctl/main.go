@@ -0,0 +1,71 @@
+// Command zysjctl is the administration tool for the zysj backend. It talks
+// to the same database as the API server and loads the same configuration
+// (CONFIG_FILE, .env and environment variables).
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jalikey/zysj-backend/internal/config"
+	"github.com/jalikey/zysj-backend/internal/database"
+	"github.com/jalikey/zysj-backend/internal/migrate"
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+const usage = `Usage: zysjctl <command> [arguments]
+
+Commands:
+  user create [-username name]           create a user, prompting for the password
+  user reset-password -username name     set a new password for an existing user
+  seed                                   insert demo categories and articles
+  reindex                                rebuild the full-text search index
+  slugs                                  generate slugs for articles that predate them
+  migrate up|down [n]|status|force <v>   manage database migrations
+`
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		log.Fatal(err)
+	}
+	database.ConnectDB(cfg.Database)
+	defer database.CloseDB()
+
+	store := repository.NewPostgresStore(database.DB)
+	if err := run(context.Background(), store, os.Args[1], os.Args[2:]); err != nil {
+		database.CloseDB()
+		log.Fatalf("zysjctl %s: %v", os.Args[1], err)
+	}
+}
+
+func run(ctx context.Context, store *repository.PostgresStore, command string, args []string) error {
+	switch command {
+	case "user":
+		return runUser(ctx, store, args)
+	case "seed":
+		return runSeed(ctx, store, store)
+	case "reindex":
+		n, err := store.RebuildSearchIndex(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reindexed %d article(s)\n", n)
+		return nil
+	case "slugs":
+		return runSlugs(ctx, store)
+	case "migrate":
+		return migrate.RunCommand(ctx, database.DB, args, os.Stdout)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
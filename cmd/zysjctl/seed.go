@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jalikey/zysj-backend/internal/content"
+	"github.com/jalikey/zysj-backend/internal/models"
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+type demoArticle struct {
+	title, format, body string
+}
+
+type demoCategory struct {
+	name, slug, description string
+	articles                []demoArticle
+}
+
+var demoData = []demoCategory{
+	{
+		name:        "中医基础",
+		slug:        "tcm-basics",
+		description: "中医基础理论入门",
+		articles: []demoArticle{
+			{"阴阳学说", content.FormatMarkdown, "# 阴阳学说\n\n阴阳是中国古代哲学的一对范畴。\n\n## 阴阳的基本内容\n\n- 阴阳对立\n- 阴阳互根\n- 阴阳消长\n- 阴阳转化\n"},
+			{"五行学说", content.FormatMarkdown, "# 五行学说\n\n五行即木、火、土、金、水五种物质的运动。\n\n## 相生\n\n木生火，火生土，土生金，金生水，水生木。\n\n## 相克\n\n木克土，土克水，水克火，火克金，金克木。\n"},
+		},
+	},
+	{
+		name:        "方剂",
+		slug:        "formulas",
+		description: "常用方剂",
+		articles: []demoArticle{
+			{"桂枝汤", content.FormatPlain, "组成：桂枝、芍药、炙甘草、生姜、大枣。\n\n功用：解肌发表，调和营卫。"},
+		},
+	},
+	{
+		name:        "中药",
+		slug:        "herbs",
+		description: "常用中药",
+		articles: []demoArticle{
+			{"甘草", content.FormatHTML, "<h2>性味归经</h2><p>甘，平。归心、肺、脾、胃经。</p><h2>功效</h2><p>补脾益气，清热解毒，祛痰止咳，缓急止痛，调和诸药。</p>"},
+		},
+	},
+}
+
+// runSeed inserts the demo categories and their articles. Categories whose
+// slug already exists are skipped together with their articles, so the
+// command can be run more than once.
+func runSeed(ctx context.Context, categories repository.CategoryStore, articles repository.ArticleStore) error {
+	for _, dc := range demoData {
+		if _, err := categories.GetCategoryBySlug(ctx, dc.slug); err == nil {
+			fmt.Printf("Category %q already exists, skipping\n", dc.slug)
+			continue
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+
+		categoryID, err := categories.CreateCategory(ctx, models.Category{
+			Name:        dc.name,
+			Slug:        dc.slug,
+			Description: dc.description,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, da := range dc.articles {
+			article := models.Article{
+				Title:         da.title,
+				Content:       da.body,
+				ContentFormat: da.format,
+				CategoryID:    models.NullInt64{Int64: categoryID, Valid: true},
+				Author:        "zysjctl",
+				Source:        "demo",
+			}
+			if err := content.PrepareArticle(&article); err != nil {
+				return err
+			}
+			if article.Slug, err = articles.UniqueArticleSlug(ctx, content.Slugify(da.title), 0); err != nil {
+				return err
+			}
+			if _, err := articles.CreateArticle(ctx, article); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Seeded category %q with %d article(s)\n", dc.slug, len(dc.articles))
+	}
+	return nil
+}
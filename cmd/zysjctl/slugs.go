@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jalikey/zysj-backend/internal/content"
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+// runSlugs replaces the placeholder slugs ("article-<id>") given to
+// articles that existed before slugs were introduced with slugs generated
+// from their titles. The placeholders are kept as redirects. Articles with
+// any other slug are left alone, so the command can be run more than once.
+func runSlugs(ctx context.Context, articles repository.ArticleStore) error {
+	fields := []string{"id", "title", "slug"}
+	updated := 0
+	for cursor := ""; ; {
+		rows, next, err := articles.GetArticlesAfter(ctx, fields, cursor, 100)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			id := row.ID
+			if row.Slug != "article-"+strconv.FormatInt(id, 10) {
+				continue
+			}
+
+			slug, err := articles.UniqueArticleSlug(ctx, content.Slugify(row.Title), id)
+			if err != nil {
+				return err
+			}
+			article, err := articles.GetArticleByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			article.Slug = slug
+			if err := articles.UpdateArticle(ctx, article); err != nil {
+				return err
+			}
+			fmt.Printf("Article %d: %s\n", id, slug)
+			updated++
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	fmt.Printf("Updated %d slug(s)\n", updated)
+	return nil
+}
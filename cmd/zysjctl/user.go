@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/jalikey/zysj-backend/internal/auth"
+	"github.com/jalikey/zysj-backend/internal/models"
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+// minPasswordLength is the shortest password accepted for new passwords.
+const minPasswordLength = 8
+
+var stdin = bufio.NewReader(os.Stdin)
+
+func runUser(ctx context.Context, store repository.UserStore, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: user create|reset-password [-username name]")
+	}
+
+	fs := flag.NewFlagSet("user "+args[0], flag.ContinueOnError)
+	username := fs.String("username", "", "name of the user")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "create":
+		return createUser(ctx, store, *username)
+	case "reset-password":
+		if *username == "" {
+			return errors.New("-username is required")
+		}
+		return resetPassword(ctx, store, *username)
+	default:
+		return fmt.Errorf("unknown user command %q", args[0])
+	}
+}
+
+func createUser(ctx context.Context, store repository.UserStore, username string) error {
+	if username == "" {
+		var err error
+		if username, err = prompt("Username: "); err != nil {
+			return err
+		}
+	}
+	if username == "" {
+		return errors.New("username cannot be empty")
+	}
+
+	if _, err := store.GetUserByUsername(ctx, username); err == nil {
+		return fmt.Errorf("user %q already exists", username)
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	password, err := promptNewPassword()
+	if err != nil {
+		return err
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	id, err := store.CreateUser(ctx, models.User{Username: username, PasswordHash: hash})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created user %q (id %d)\n", username, id)
+	return nil
+}
+
+func resetPassword(ctx context.Context, store repository.UserStore, username string) error {
+	password, err := promptNewPassword()
+	if err != nil {
+		return err
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := store.UpdateUserPassword(ctx, username, hash); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("user %q does not exist", username)
+		}
+		return err
+	}
+	fmt.Printf("Password updated for %q\n", username)
+	return nil
+}
+
+// promptNewPassword asks for a password twice and checks that both match.
+func promptNewPassword() (string, error) {
+	password, err := promptSecret("Password: ")
+	if err != nil {
+		return "", err
+	}
+	if len(password) < minPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	confirm, err := promptSecret("Confirm password: ")
+	if err != nil {
+		return "", err
+	}
+	if password != confirm {
+		return "", errors.New("passwords do not match")
+	}
+	return password, nil
+}
+
+func prompt(label string) (string, error) {
+	fmt.Print(label)
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// promptSecret reads a line without echoing it when stdin is a terminal,
+// and reads it normally when input is piped in from a script.
+func promptSecret(label string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return prompt(label)
+	}
+	fmt.Print(label)
+	b, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,7 @@
+-- 000001_init_schema.down.sql
+
+DROP TRIGGER IF EXISTS update_articles_updated_at ON articles;
+DROP FUNCTION IF EXISTS update_updated_at_column();
+
+DROP TABLE IF EXISTS "articles";
+DROP TABLE IF EXISTS "categories";
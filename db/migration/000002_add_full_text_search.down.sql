@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS content_tsv_idx;
+DROP TRIGGER IF EXISTS tsvectorupdate ON articles;
+DROP FUNCTION IF EXISTS articles_tsvector_update();
+ALTER TABLE articles DROP COLUMN IF EXISTS content_tsv;
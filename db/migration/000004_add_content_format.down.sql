@@ -0,0 +1,3 @@
+ALTER TABLE articles DROP CONSTRAINT IF EXISTS articles_content_format_check;
+ALTER TABLE articles DROP COLUMN IF EXISTS content_html;
+ALTER TABLE articles DROP COLUMN IF EXISTS content_format;
@@ -0,0 +1,7 @@
+-- Store the declared source format of each article together with the
+-- sanitised HTML rendered from it when the article is saved.
+ALTER TABLE articles ADD COLUMN content_format varchar(16) NOT NULL DEFAULT 'plain';
+ALTER TABLE articles ADD COLUMN content_html text NOT NULL DEFAULT '';
+
+ALTER TABLE articles ADD CONSTRAINT articles_content_format_check
+  CHECK (content_format IN ('plain', 'markdown', 'html'));
@@ -0,0 +1,2 @@
+-- Table of contents extracted from the rendered article headings
+ALTER TABLE articles ADD COLUMN toc jsonb NOT NULL DEFAULT '[]';
@@ -0,0 +1,3 @@
+ALTER TABLE articles DROP COLUMN IF EXISTS char_count;
+ALTER TABLE articles DROP COLUMN IF EXISTS word_count;
+ALTER TABLE articles DROP COLUMN IF EXISTS excerpt;
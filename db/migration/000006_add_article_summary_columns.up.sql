@@ -0,0 +1,11 @@
+-- Summary columns so list endpoints don't have to read the full content.
+-- They are computed from the rendered content whenever an article is saved.
+ALTER TABLE articles ADD COLUMN excerpt text NOT NULL DEFAULT '';
+ALTER TABLE articles ADD COLUMN word_count integer NOT NULL DEFAULT 0;
+ALTER TABLE articles ADD COLUMN char_count integer NOT NULL DEFAULT 0;
+
+-- Approximate values for existing rows; saving an article recomputes them
+UPDATE articles SET
+    excerpt = left(regexp_replace(regexp_replace(content, '<[^>]*>', ' ', 'g'), '\s+', ' ', 'g'), 200),
+    word_count = (SELECT count(*) FROM regexp_matches(content, '[一-鿿]|[A-Za-z0-9]+', 'g')),
+    char_count = char_length(regexp_replace(content, '\s', '', 'g'));
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS articles_category_created_at_id_idx;
+DROP INDEX IF EXISTS articles_created_at_id_idx;
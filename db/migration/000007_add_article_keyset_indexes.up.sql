@@ -0,0 +1,3 @@
+-- Indexes backing keyset pagination over (created_at, id)
+CREATE INDEX articles_created_at_id_idx ON articles (created_at DESC, id DESC);
+CREATE INDEX articles_category_created_at_id_idx ON articles (category_id, created_at DESC, id DESC);
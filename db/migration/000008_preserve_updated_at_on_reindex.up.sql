@@ -0,0 +1,15 @@
+-- Let maintenance jobs rewrite rows without touching their modification
+-- time: the updated_at trigger leaves it alone while the transaction has
+-- zysj.preserve_updated_at set (SET LOCAL or set_config(..., true)). This
+-- replaces disabling the trigger, which locks the whole table and needs
+-- ownership of it.
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+   IF current_setting('zysj.preserve_updated_at', true) = 'on' THEN
+      RETURN NEW;
+   END IF;
+   NEW.updated_at = now();
+   RETURN NEW;
+END;
+$$ language 'plpgsql';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS category_slug_redirects;
+DROP TABLE IF EXISTS article_slug_redirects;
+ALTER TABLE articles DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,25 @@
+-- Articles get a slug so they can be addressed by URL instead of by id.
+-- Existing rows get a placeholder; `zysjctl slugs` regenerates them from
+-- the titles.
+ALTER TABLE articles ADD COLUMN slug varchar(255);
+UPDATE articles SET slug = 'article-' || id;
+ALTER TABLE articles ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE articles ADD CONSTRAINT articles_slug_key UNIQUE (slug);
+ALTER TABLE articles ADD CONSTRAINT articles_slug_check CHECK (slug <> '');
+
+-- Slugs that articles and categories used before being renamed, so that
+-- old URLs keep resolving. A slug is removed from here as soon as a record
+-- takes it again.
+CREATE TABLE article_slug_redirects (
+  slug varchar(255) PRIMARY KEY,
+  article_id bigint NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX ON article_slug_redirects (article_id);
+
+CREATE TABLE category_slug_redirects (
+  slug varchar(255) PRIMARY KEY,
+  category_id bigint NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX ON category_slug_redirects (category_id);
@@ -0,0 +1,5 @@
+-- Row versions for optimistic concurrency. Every update increments the
+-- version, which is exposed as the ETag; writes sent with an If-Match for
+-- an older version are rejected.
+ALTER TABLE articles ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE categories ADD COLUMN version bigint NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS categories_notify_cache ON categories;
+DROP TRIGGER IF EXISTS articles_notify_cache ON articles;
+DROP FUNCTION IF EXISTS notify_cache_invalidation();
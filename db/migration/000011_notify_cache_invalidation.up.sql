@@ -0,0 +1,18 @@
+-- Announce changes to articles and categories on the zysj_cache channel,
+-- with the cache namespace as payload, so that every API instance can drop
+-- its cached reads. Notifications are sent when the transaction commits,
+-- and repeated ones within a transaction are folded into one.
+CREATE OR REPLACE FUNCTION notify_cache_invalidation() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('zysj_cache', TG_ARGV[0]);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER articles_notify_cache
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON articles
+FOR EACH STATEMENT EXECUTE FUNCTION notify_cache_invalidation('articles');
+
+CREATE TRIGGER categories_notify_cache
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON categories
+FOR EACH STATEMENT EXECUTE FUNCTION notify_cache_invalidation('categories');
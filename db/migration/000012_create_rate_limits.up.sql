@@ -0,0 +1,12 @@
+-- Token buckets shared by the API instances when RATE_LIMIT_BACKEND is
+-- postgres. The table is unlogged: losing it in a crash only resets the
+-- limits. Buckets that have refilled (full_at has passed) are swept
+-- periodically.
+CREATE UNLOGGED TABLE rate_limits (
+    key text PRIMARY KEY,
+    tokens double precision NOT NULL,
+    updated_at timestamptz NOT NULL,
+    full_at timestamptz NOT NULL
+);
+
+CREATE INDEX rate_limits_full_at_idx ON rate_limits (full_at);
@@ -0,0 +1,15 @@
+-- API keys for scripts calling the admin API. Only the SHA-256 hash of a
+-- key is stored; the prefix is its first characters, shown in listings to
+-- tell keys apart. Revoked and expired keys are kept for auditing.
+CREATE TABLE "api_keys" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar(255) NOT NULL,
+  "prefix" varchar(32) NOT NULL,
+  "key_hash" char(64) UNIQUE NOT NULL,
+  "scopes" text[] NOT NULL,
+  "created_by" varchar(255) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz,
+  "revoked_at" timestamptz,
+  "last_used_at" timestamptz
+);
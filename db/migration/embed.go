@@ -0,0 +1,10 @@
+// Package migration embeds the SQL migration scripts so that they ship
+// inside the binaries instead of alongside them.
+package migration
+
+import "embed"
+
+// FS holds every NNNNNN_name.up.sql and NNNNNN_name.down.sql script.
+//
+//go:embed *.sql
+var FS embed.FS
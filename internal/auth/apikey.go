@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, which tells them apart from JWTs in
+// the Authorization header and makes leaked keys easy to search for.
+const APIKeyPrefix = "zysj_"
+
+// Scopes grantable to API keys. Users logged in with a JWT have them all.
+const (
+	ScopeRead            = "read"             // Admin GET endpoints
+	ScopeArticlesWrite   = "articles:write"   // Create, update and delete articles
+	ScopeCategoriesWrite = "categories:write" // Create, update and delete categories
+)
+
+// apiKeyDisplayLength is how much of a key is kept in clear to identify it.
+const apiKeyDisplayLength = len(APIKeyPrefix) + 8
+
+// GenerateAPIKey returns a new random API key, the prefix identifying it
+// and the hash to store.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	key = APIKeyPrefix + hex.EncodeToString(b)
+	return key, key[:apiKeyDisplayLength], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the stored hash of key. Keys are long and random, so
+// a fast hash is enough; unlike passwords they can't be guessed.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether the bearer token is an API key rather than a
+// JWT.
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
@@ -2,26 +2,37 @@ package auth
 
 import (
     "fmt"
-    "os"
     "time"
 
     "github.com/golang-jwt/jwt/v5"
+
+    "github.com/jalikey/zysj-backend/internal/config"
 )
 
-// A secret key for signing the tokens. In production, use a secure key from env variables.
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+// The secret key for signing the tokens and how long tokens stay valid, set by Configure.
+var (
+    jwtSecret []byte
+    tokenTTL  = 72 * time.Hour
+)
+
+// Configure sets the signing secret and token lifetime. It must be called
+// at startup, before any token is generated or validated.
+func Configure(cfg config.AuthConfig) {
+    jwtSecret = []byte(cfg.JWTSecret)
+    tokenTTL = time.Duration(cfg.TokenTTL)
+}
 
 // GenerateJWT creates a new JWT for a given username.
 func GenerateJWT(username string) (string, error) {
     if len(jwtSecret) == 0 {
-        return "", fmt.Errorf("JWT_SECRET environment variable not set")
+        return "", fmt.Errorf("JWT secret not configured")
     }
 
     // Create a new token object, specifying signing method and the claims
     token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
         "username": username,
-        "exp":      time.Now().Add(time.Hour * 72).Unix(), // Token expires in 72 hours
-        "iat":      time.Now().Unix(),                      // Issued at
+        "exp":      time.Now().Add(tokenTTL).Unix(), // Token expires after the configured TTL
+        "iat":      time.Now().Unix(),               // Issued at
     })
 
     // Sign and get the complete encoded token as a string using the secret
@@ -36,6 +47,9 @@ func ValidateJWT(tokenString string) (*jwt.Token, error) {
         if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
             return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
         }
+        if len(jwtSecret) == 0 {
+            return nil, fmt.Errorf("JWT secret not configured")
+        }
         return jwtSecret, nil
     })
 }
\ No newline at end of file
@@ -0,0 +1,28 @@
+// Package cache puts a read cache in front of the article and category
+// stores.
+//
+// Cached values are kept in a Store under keys that embed a generation
+// per namespace ("articles" and "categories"). Writes don't hunt down the
+// keys they affect: they drop the generation, so that every key of the
+// namespace is abandoned at once and ages out of the store. The default
+// Store is an in-process LRU; anything offering Get, Set and Delete on
+// byte values, such as Redis, can stand in for it.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a key/value store for encoded cache entries. Implementations
+// must be safe for concurrent use. Failures are not reported: a Store that
+// can't serve a value behaves as if it didn't have it.
+type Store interface {
+	// Get returns the value stored under key, if it is present and has
+	// not expired.
+	Get(ctx context.Context, key string) ([]byte, bool)
+	// Set stores value under key for ttl, or until evicted when ttl is 0.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+	// Delete removes the given keys.
+	Delete(ctx context.Context, keys ...string)
+}
@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	type op struct {
+		kind  string // "set", "get" or "delete"
+		key   string
+		value string
+	}
+
+	tests := []struct {
+		name     string
+		capacity int
+		ops      []op
+		present  map[string]string
+		absent   []string
+	}{
+		{
+			name:     "set and get",
+			capacity: 2,
+			ops:      []op{{"set", "a", "1"}},
+			present:  map[string]string{"a": "1"},
+			absent:   []string{"b"},
+		},
+		{
+			name:     "overwrite",
+			capacity: 2,
+			ops:      []op{{"set", "a", "1"}, {"set", "a", "2"}},
+			present:  map[string]string{"a": "2"},
+		},
+		{
+			name:     "evicts least recently set",
+			capacity: 2,
+			ops:      []op{{"set", "a", "1"}, {"set", "b", "2"}, {"set", "c", "3"}},
+			present:  map[string]string{"b": "2", "c": "3"},
+			absent:   []string{"a"},
+		},
+		{
+			name:     "get refreshes recency",
+			capacity: 2,
+			ops:      []op{{"set", "a", "1"}, {"set", "b", "2"}, {"get", "a", ""}, {"set", "c", "3"}},
+			present:  map[string]string{"a": "1", "c": "3"},
+			absent:   []string{"b"},
+		},
+		{
+			name:     "overwrite refreshes recency",
+			capacity: 2,
+			ops:      []op{{"set", "a", "1"}, {"set", "b", "2"}, {"set", "a", "9"}, {"set", "c", "3"}},
+			present:  map[string]string{"a": "9", "c": "3"},
+			absent:   []string{"b"},
+		},
+		{
+			name:     "delete",
+			capacity: 2,
+			ops:      []op{{"set", "a", "1"}, {"set", "b", "2"}, {"delete", "a", ""}, {"delete", "missing", ""}},
+			present:  map[string]string{"b": "2"},
+			absent:   []string{"a", "missing"},
+		},
+		{
+			name:     "deleted entry frees its slot",
+			capacity: 2,
+			ops:      []op{{"set", "a", "1"}, {"set", "b", "2"}, {"delete", "b", ""}, {"set", "c", "3"}},
+			present:  map[string]string{"a": "1", "c": "3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			l := NewLRU(tt.capacity)
+			for _, o := range tt.ops {
+				switch o.kind {
+				case "set":
+					l.Set(ctx, o.key, []byte(o.value), 0)
+				case "get":
+					l.Get(ctx, o.key)
+				case "delete":
+					l.Delete(ctx, o.key)
+				}
+			}
+			for key, want := range tt.present {
+				if got, ok := l.Get(ctx, key); !ok || string(got) != want {
+					t.Errorf("Get(%q) = %q, %v, want %q", key, got, ok, want)
+				}
+			}
+			for _, key := range tt.absent {
+				if got, ok := l.Get(ctx, key); ok {
+					t.Errorf("Get(%q) = %q, want no entry", key, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	ctx := context.Background()
+	l := NewLRU(10)
+	l.Set(ctx, "short", []byte("x"), 10*time.Millisecond)
+	l.Set(ctx, "long", []byte("y"), time.Hour)
+	l.Set(ctx, "forever", []byte("z"), 0)
+
+	if _, ok := l.Get(ctx, "short"); !ok {
+		t.Fatal("entry expired straight away")
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"short", false},
+		{"long", true},
+		{"forever", true},
+	}
+	for _, tt := range tests {
+		if _, ok := l.Get(ctx, tt.key); ok != tt.want {
+			t.Errorf("Get(%q) present = %v, want %v", tt.key, ok, tt.want)
+		}
+	}
+	if _, ok := l.entries["short"]; ok {
+		t.Error("expired entry still held")
+	}
+}
+
+func TestLRUConcurrent(t *testing.T) {
+	ctx := context.Background()
+	l := NewLRU(50)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Go(func() {
+			for i := 0; i < 500; i++ {
+				key := strconv.Itoa((g*31 + i) % 100)
+				l.Set(ctx, key, []byte(key), time.Minute)
+				l.Get(ctx, key)
+				if i%7 == 0 {
+					l.Delete(ctx, key)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	if l.order.Len() > 50 || len(l.entries) != l.order.Len() {
+		t.Errorf("holding %d entries in order and %d in the map, capacity 50", l.order.Len(), len(l.entries))
+	}
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NotifyChannel is the Postgres channel on which triggers on the articles
+// and categories tables announce changes, with the namespace to invalidate
+// as payload.
+const NotifyChannel = "zysj_cache"
+
+// Delays between attempts to re-establish the listen connection.
+const (
+	minListenBackoff = time.Second
+	maxListenBackoff = 30 * time.Second
+)
+
+// Listen invalidates cached reads when another instance, or anything else
+// writing to the database, changes articles or categories. It holds a
+// connection from pool listening on NotifyChannel, reconnects with backoff
+// when the connection drops, and returns once ctx is done.
+//
+// Writes made through r invalidate the cache straight away and are
+// announced too, so they also invalidate it a second time shortly after.
+func (r *Repository) Listen(ctx context.Context, pool *pgxpool.Pool) {
+	r.listenWith(ctx, func(ctx context.Context) (subscription, error) {
+		return subscribe(ctx, pool)
+	})
+}
+
+// subscription is a connection listening on NotifyChannel.
+type subscription interface {
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close()
+}
+
+// listenWith runs the loop of Listen, subscribing with subscribe.
+func (r *Repository) listenWith(ctx context.Context, subscribe func(context.Context) (subscription, error)) {
+	backoff := minListenBackoff
+	for {
+		listening, err := r.listen(ctx, subscribe)
+		if ctx.Err() != nil {
+			return
+		}
+		if listening {
+			backoff = minListenBackoff
+		}
+		slog.WarnContext(ctx, "Cache invalidation listener disconnected, reconnecting", "error", err, "retry_in", backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, maxListenBackoff)
+	}
+}
+
+// listen subscribes and applies notifications until the subscription fails
+// or ctx is done. It reports whether the subscription was established.
+func (r *Repository) listen(ctx context.Context, subscribe func(context.Context) (subscription, error)) (bool, error) {
+	sub, err := subscribe(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer sub.Close()
+
+	// Changes made while no connection was listening went unannounced
+	r.InvalidateCategories(ctx)
+	slog.InfoContext(ctx, "Listening for cache invalidations", "channel", NotifyChannel)
+
+	for {
+		n, err := sub.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		switch n.Payload {
+		case articlesNamespace:
+			r.InvalidateArticles(ctx)
+		case categoriesNamespace:
+			r.InvalidateCategories(ctx)
+		default:
+			slog.WarnContext(ctx, "Ignoring unknown cache invalidation", "payload", n.Payload)
+		}
+	}
+}
+
+// poolSubscription is a pool connection listening on NotifyChannel.
+type poolSubscription struct {
+	conn *pgxpool.Conn
+}
+
+// subscribe acquires a connection from pool and listens on NotifyChannel.
+func subscribe(ctx context.Context, pool *pgxpool.Pool) (subscription, error) {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sub := poolSubscription{conn}
+	if _, err := conn.Exec(ctx, "LISTEN "+NotifyChannel); err != nil {
+		sub.Close()
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s poolSubscription) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	return s.conn.Conn().WaitForNotification(ctx)
+}
+
+// Close closes the connection rather than hand it back to other users of
+// the pool, as it is subscribed.
+func (s poolSubscription) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.conn.Conn().Close(ctx)
+	s.conn.Release()
+}
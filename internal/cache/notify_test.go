@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeSubscription delivers the payloads sent on notes, and fails as if the
+// connection dropped once notes is closed.
+type fakeSubscription struct {
+	notes chan string
+}
+
+func (s *fakeSubscription) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case payload, ok := <-s.notes:
+		if !ok {
+			return nil, errors.New("connection lost")
+		}
+		return &pgconn.Notification{Channel: NotifyChannel, Payload: payload}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *fakeSubscription) Close() {}
+
+func TestListen(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := New(NewLRU(10), time.Minute, nil, nil)
+
+	// valid starts a generation of each namespace; invalidated reports
+	// which have been dropped since
+	valid := func() {
+		r.generation(ctx, articlesNamespace)
+		r.generation(ctx, categoriesNamespace)
+	}
+	invalidated := func(namespace string) bool {
+		_, ok := r.store.Get(ctx, generationKey(namespace))
+		return !ok
+	}
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+		}
+	}
+
+	subs := make(chan *fakeSubscription, 2)
+	first, second := &fakeSubscription{notes: make(chan string)}, &fakeSubscription{notes: make(chan string)}
+	subs <- first
+	done := make(chan struct{})
+	valid()
+	go func() {
+		defer close(done)
+		r.listenWith(ctx, func(context.Context) (subscription, error) { return <-subs, nil })
+	}()
+
+	waitFor("invalidation on subscribing", func() bool { return invalidated(articlesNamespace) && invalidated(categoriesNamespace) })
+
+	tests := []struct {
+		payload        string
+		wantArticles   bool
+		wantCategories bool
+	}{
+		{"unknown", false, false},
+		{articlesNamespace, true, false},
+		{categoriesNamespace, true, true},
+	}
+	for _, tt := range tests {
+		valid()
+		first.notes <- tt.payload
+		// The next payload is only taken once this one was applied
+		first.notes <- "unknown"
+		if got := invalidated(articlesNamespace); got != tt.wantArticles {
+			t.Errorf("%s: articles invalidated = %v, want %v", tt.payload, got, tt.wantArticles)
+		}
+		if got := invalidated(categoriesNamespace); got != tt.wantCategories {
+			t.Errorf("%s: categories invalidated = %v, want %v", tt.payload, got, tt.wantCategories)
+		}
+	}
+
+	// Dropping the connection resubscribes after a backoff, invalidating
+	// everything that changed in between
+	valid()
+	subs <- second
+	close(first.notes)
+	waitFor("invalidation on resubscribing", func() bool { return invalidated(articlesNamespace) && invalidated(categoriesNamespace) })
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener did not stop")
+	}
+}
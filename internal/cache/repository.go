@@ -0,0 +1,273 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/jalikey/zysj-backend/internal/metrics"
+	"github.com/jalikey/zysj-backend/internal/models"
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+// Cache namespaces. Category writes invalidate articles too, as article
+// lists include category names and deleting a category deletes its
+// articles.
+const (
+	articlesNamespace   = "articles"
+	categoriesNamespace = "categories"
+)
+
+// Repository is an ArticleStore and CategoryStore that caches category
+// reads, single article lookups and article list pages. Search results and
+// slug lookups used by writes are passed through. Writes go to the wrapped
+// stores and then invalidate what they may have changed.
+type Repository struct {
+	repository.ArticleStore
+	repository.CategoryStore
+
+	store Store
+	ttl   time.Duration
+	loads singleflight.Group
+}
+
+// New returns a Repository caching reads from articles and categories in
+// store for ttl.
+func New(store Store, ttl time.Duration, articles repository.ArticleStore, categories repository.CategoryStore) *Repository {
+	return &Repository{ArticleStore: articles, CategoryStore: categories, store: store, ttl: ttl}
+}
+
+var (
+	_ repository.ArticleStore  = (*Repository)(nil)
+	_ repository.CategoryStore = (*Repository)(nil)
+)
+
+// InvalidateArticles drops every cached article read.
+func (r *Repository) InvalidateArticles(ctx context.Context) {
+	r.store.Delete(ctx, generationKey(articlesNamespace))
+}
+
+// InvalidateCategories drops every cached category read, and the article
+// reads that depend on categories.
+func (r *Repository) InvalidateCategories(ctx context.Context) {
+	r.store.Delete(ctx, generationKey(categoriesNamespace), generationKey(articlesNamespace))
+}
+
+// --- Articles ---
+
+// page is a cached page of an article list.
+type page struct {
+	Rows []models.ArticleSummary
+	Info repository.PageInfo
+	Next string
+}
+
+func (r *Repository) GetAllArticles(ctx context.Context, fields []string, limit, offset int, count repository.CountMode) ([]models.ArticleSummary, repository.PageInfo, error) {
+	key := fmt.Sprintf("list:%s:%d:%d:%d", strings.Join(fields, ","), limit, offset, count)
+	p, err := load(ctx, r, articlesNamespace, "article_list", key, func(ctx context.Context) (page, error) {
+		rows, info, err := r.ArticleStore.GetAllArticles(ctx, fields, limit, offset, count)
+		return page{Rows: rows, Info: info}, err
+	})
+	return p.Rows, p.Info, err
+}
+
+func (r *Repository) GetArticlesAfter(ctx context.Context, fields []string, after string, limit int) ([]models.ArticleSummary, string, error) {
+	key := fmt.Sprintf("after:%s:%s:%d", strings.Join(fields, ","), after, limit)
+	p, err := load(ctx, r, articlesNamespace, "article_list", key, func(ctx context.Context) (page, error) {
+		rows, next, err := r.ArticleStore.GetArticlesAfter(ctx, fields, after, limit)
+		return page{Rows: rows, Next: next}, err
+	})
+	return p.Rows, p.Next, err
+}
+
+func (r *Repository) GetArticlesByCategoryID(ctx context.Context, categoryID int64, fields []string, limit, offset int, count repository.CountMode) ([]models.ArticleSummary, repository.PageInfo, error) {
+	key := fmt.Sprintf("category:%d:list:%s:%d:%d:%d", categoryID, strings.Join(fields, ","), limit, offset, count)
+	p, err := load(ctx, r, articlesNamespace, "article_list", key, func(ctx context.Context) (page, error) {
+		rows, info, err := r.ArticleStore.GetArticlesByCategoryID(ctx, categoryID, fields, limit, offset, count)
+		return page{Rows: rows, Info: info}, err
+	})
+	return p.Rows, p.Info, err
+}
+
+func (r *Repository) GetArticlesByCategoryIDAfter(ctx context.Context, categoryID int64, fields []string, after string, limit int) ([]models.ArticleSummary, string, error) {
+	key := fmt.Sprintf("category:%d:after:%s:%s:%d", categoryID, strings.Join(fields, ","), after, limit)
+	p, err := load(ctx, r, articlesNamespace, "article_list", key, func(ctx context.Context) (page, error) {
+		rows, next, err := r.ArticleStore.GetArticlesByCategoryIDAfter(ctx, categoryID, fields, after, limit)
+		return page{Rows: rows, Next: next}, err
+	})
+	return p.Rows, p.Next, err
+}
+
+func (r *Repository) GetArticleByID(ctx context.Context, id int64) (models.Article, error) {
+	return load(ctx, r, articlesNamespace, "article", fmt.Sprintf("id:%d", id), func(ctx context.Context) (models.Article, error) {
+		return r.ArticleStore.GetArticleByID(ctx, id)
+	})
+}
+
+func (r *Repository) GetArticleBySlug(ctx context.Context, slug string) (models.Article, error) {
+	return load(ctx, r, articlesNamespace, "article", "slug:"+slug, func(ctx context.Context) (models.Article, error) {
+		return r.ArticleStore.GetArticleBySlug(ctx, slug)
+	})
+}
+
+func (r *Repository) CreateArticle(ctx context.Context, article models.Article) (int64, error) {
+	id, err := r.ArticleStore.CreateArticle(ctx, article)
+	if err == nil {
+		r.InvalidateArticles(ctx)
+	}
+	return id, err
+}
+
+func (r *Repository) UpdateArticle(ctx context.Context, article models.Article) error {
+	err := r.ArticleStore.UpdateArticle(ctx, article)
+	if err == nil {
+		r.InvalidateArticles(ctx)
+	}
+	return err
+}
+
+func (r *Repository) DeleteArticle(ctx context.Context, id int64) error {
+	err := r.ArticleStore.DeleteArticle(ctx, id)
+	if err == nil {
+		r.InvalidateArticles(ctx)
+	}
+	return err
+}
+
+// --- Categories ---
+
+func (r *Repository) GetAllCategories(ctx context.Context) ([]models.Category, error) {
+	return load(ctx, r, categoriesNamespace, "category_list", "all", r.CategoryStore.GetAllCategories)
+}
+
+func (r *Repository) GetCategoryByID(ctx context.Context, id int64) (models.Category, error) {
+	return load(ctx, r, categoriesNamespace, "category", fmt.Sprintf("id:%d", id), func(ctx context.Context) (models.Category, error) {
+		return r.CategoryStore.GetCategoryByID(ctx, id)
+	})
+}
+
+func (r *Repository) GetCategoryBySlug(ctx context.Context, slug string) (models.Category, error) {
+	return load(ctx, r, categoriesNamespace, "category", "slug:"+slug, func(ctx context.Context) (models.Category, error) {
+		return r.CategoryStore.GetCategoryBySlug(ctx, slug)
+	})
+}
+
+func (r *Repository) CreateCategory(ctx context.Context, category models.Category) (int64, error) {
+	id, err := r.CategoryStore.CreateCategory(ctx, category)
+	if err == nil {
+		r.InvalidateCategories(ctx)
+	}
+	return id, err
+}
+
+func (r *Repository) UpdateCategory(ctx context.Context, category models.Category) error {
+	err := r.CategoryStore.UpdateCategory(ctx, category)
+	if err == nil {
+		r.InvalidateCategories(ctx)
+	}
+	return err
+}
+
+func (r *Repository) DeleteCategory(ctx context.Context, id int64) error {
+	err := r.CategoryStore.DeleteCategory(ctx, id)
+	if err == nil {
+		r.InvalidateCategories(ctx)
+	}
+	return err
+}
+
+// bypassKey marks contexts whose reads skip the cache.
+type bypassKey struct{}
+
+// Bypass returns a context whose reads go straight to the wrapped stores.
+// Writes use it to read the version they are based on, as a cached copy
+// may lag behind a write made through another instance.
+func Bypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// --- Helpers ---
+
+// load returns the value cached under key in namespace, or loads it with
+// fetch and caches it. Concurrent misses for the same key share a single
+// fetch. kind labels the lookup in the metrics. Errors are not cached, and
+// contexts from Bypass always fetch.
+func load[T any](ctx context.Context, r *Repository, namespace, kind, key string, fetch func(context.Context) (T, error)) (T, error) {
+	if ctx.Value(bypassKey{}) != nil {
+		return fetch(ctx)
+	}
+	key = namespace + ":" + r.generation(ctx, namespace) + ":" + key
+
+	var value T
+	if data, ok := r.store.Get(ctx, key); ok {
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err == nil {
+			metrics.CacheHit(kind)
+			return value, nil
+		}
+		r.store.Delete(ctx, key)
+	}
+	metrics.CacheMiss(kind)
+
+	result := r.loads.DoChan(key, func() (interface{}, error) {
+		v, err := fetch(ctx)
+		if err != nil {
+			return v, err
+		}
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+			slog.WarnContext(ctx, "Error encoding cache entry", "key", key, "error", err)
+		} else {
+			r.store.Set(ctx, key, buf.Bytes(), r.ttl)
+		}
+		return v, nil
+	})
+
+	select {
+	case res := <-result:
+		if res.Shared {
+			metrics.CacheSharedLoad(kind)
+		}
+		// The shared load ran with the context of the request that started
+		// it; if that request went away, load again for this one
+		if res.Err != nil && isContextErr(res.Err) && ctx.Err() == nil {
+			return fetch(ctx)
+		}
+		if res.Err != nil {
+			return value, res.Err
+		}
+		return res.Val.(T), nil
+	case <-ctx.Done():
+		return value, ctx.Err()
+	}
+}
+
+// generation returns the current generation of namespace, starting a new
+// one if it was invalidated.
+func (r *Repository) generation(ctx context.Context, namespace string) string {
+	key := generationKey(namespace)
+	if gen, ok := r.store.Get(ctx, key); ok {
+		return string(gen)
+	}
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	gen := hex.EncodeToString(b)
+	r.store.Set(ctx, key, []byte(gen), 0)
+	return gen
+}
+
+func generationKey(namespace string) string {
+	return "generation:" + namespace
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,533 @@
+// Package config loads and validates the application configuration.
+//
+// Values are resolved in increasing order of precedence: built-in defaults,
+// an optional YAML or TOML file, the .env file and finally the process
+// environment.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the complete application configuration.
+type Config struct {
+	HTTP      HTTPConfig      `yaml:"http" toml:"http"`
+	Database  DatabaseConfig  `yaml:"database" toml:"database"`
+	Auth      AuthConfig      `yaml:"auth" toml:"auth"`
+	Log       LogConfig       `yaml:"log" toml:"log"`
+	ReadCache ReadCacheConfig `yaml:"read_cache" toml:"read_cache"`
+}
+
+// HTTPConfig configures the API server.
+type HTTPConfig struct {
+	Port            string          `yaml:"port" toml:"port"`                         // API_PORT
+	InternalPort    string          `yaml:"internal_port" toml:"internal_port"`       // INTERNAL_PORT, for /metrics and detailed /readyz; empty disables
+	ShutdownTimeout Duration        `yaml:"shutdown_timeout" toml:"shutdown_timeout"` // SHUTDOWN_TIMEOUT
+	Timeouts        TimeoutsConfig  `yaml:"timeouts" toml:"timeouts"`
+	Cache           CacheConfig     `yaml:"cache" toml:"cache"`
+	RateLimit       RateLimitConfig `yaml:"rate_limit" toml:"rate_limit"`
+	CORS            CORSConfig      `yaml:"cors" toml:"cors"`
+	Security        SecurityConfig  `yaml:"security" toml:"security"`
+	// TRUSTED_PROXIES, comma-separated IPs or CIDRs of the load balancers
+	// whose X-Forwarded-For and X-Real-IP headers are believed. Empty trusts
+	// none, so the client IP is the address of the connection.
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+}
+
+// TimeoutsConfig bounds how long a request may spend on database queries
+// before it is cancelled. Zero disables the deadline.
+type TimeoutsConfig struct {
+	Default Duration `yaml:"default" toml:"default"` // QUERY_TIMEOUT
+	Search  Duration `yaml:"search" toml:"search"`   // SEARCH_QUERY_TIMEOUT
+	Admin   Duration `yaml:"admin" toml:"admin"`     // ADMIN_QUERY_TIMEOUT
+}
+
+// CacheConfig sets the Cache-Control header of public read endpoints. When
+// both ages are zero, responses may be stored but must be revalidated.
+type CacheConfig struct {
+	MaxAge               Duration `yaml:"max_age" toml:"max_age"`                               // CACHE_MAX_AGE, for browsers
+	SharedMaxAge         Duration `yaml:"shared_max_age" toml:"shared_max_age"`                 // CACHE_SHARED_MAX_AGE, for CDNs and proxies
+	StaleWhileRevalidate Duration `yaml:"stale_while_revalidate" toml:"stale_while_revalidate"` // CACHE_STALE_WHILE_REVALIDATE
+}
+
+// CORSConfig lets browser frontends on other origins call the API. With no
+// allowed origins, cross-origin requests get no CORS headers.
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins" toml:"allowed_origins"`     // CORS_ALLOWED_ORIGINS, comma-separated, "*" for any
+	AllowedMethods   []string `yaml:"allowed_methods" toml:"allowed_methods"`     // CORS_ALLOWED_METHODS, comma-separated
+	AllowedHeaders   []string `yaml:"allowed_headers" toml:"allowed_headers"`     // CORS_ALLOWED_HEADERS, comma-separated
+	AllowCredentials bool     `yaml:"allow_credentials" toml:"allow_credentials"` // CORS_ALLOW_CREDENTIALS
+	MaxAge           Duration `yaml:"max_age" toml:"max_age"`                     // CORS_MAX_AGE, how long browsers may cache preflights
+}
+
+// SecurityConfig sets the security headers sent with every response.
+// Empty values and a zero HSTS max age leave the header out.
+type SecurityConfig struct {
+	ContentSecurityPolicy string   `yaml:"content_security_policy" toml:"content_security_policy"` // SECURITY_CSP
+	FrameOptions          string   `yaml:"frame_options" toml:"frame_options"`                     // SECURITY_FRAME_OPTIONS: DENY or SAMEORIGIN
+	HSTSMaxAge            Duration `yaml:"hsts_max_age" toml:"hsts_max_age"`                       // SECURITY_HSTS_MAX_AGE
+}
+
+// RateLimitConfig throttles each client separately per route group.
+// Clients are told apart by user once authenticated, by IP otherwise.
+type RateLimitConfig struct {
+	Backend string        `yaml:"backend" toml:"backend"` // RATE_LIMIT_BACKEND: memory, or postgres to share limits between instances
+	Public  RateLimitRule `yaml:"public" toml:"public"`   // RATE_LIMIT_PUBLIC, RATE_LIMIT_PUBLIC_BURST
+	Search  RateLimitRule `yaml:"search" toml:"search"`   // RATE_LIMIT_SEARCH, RATE_LIMIT_SEARCH_BURST
+	Login   RateLimitRule `yaml:"login" toml:"login"`     // RATE_LIMIT_LOGIN, RATE_LIMIT_LOGIN_BURST
+	Admin   RateLimitRule `yaml:"admin" toml:"admin"`     // RATE_LIMIT_ADMIN, RATE_LIMIT_ADMIN_BURST
+}
+
+// RateLimitRule lets a client make Burst requests at once, then Rate
+// requests on average. A zero Rate disables the limit.
+type RateLimitRule struct {
+	Rate  Rate `yaml:"rate" toml:"rate"`
+	Burst int  `yaml:"burst" toml:"burst"`
+}
+
+// DatabaseConfig configures the PostgreSQL connection pool.
+type DatabaseConfig struct {
+	Host        string `yaml:"host" toml:"host"`                 // DB_HOST
+	Port        string `yaml:"port" toml:"port"`                 // DB_PORT
+	User        string `yaml:"user" toml:"user"`                 // DB_USER
+	Password    string `yaml:"password" toml:"password"`         // DB_PASSWORD
+	Name        string `yaml:"name" toml:"name"`                 // DB_NAME
+	SSLMode     string `yaml:"ssl_mode" toml:"ssl_mode"`         // DB_SSL_MODE
+	AutoMigrate bool   `yaml:"auto_migrate" toml:"auto_migrate"` // DB_AUTO_MIGRATE
+	// DB_TRACE_QUERY_TEXT records the SQL text on query spans, which are
+	// otherwise named after the repository operation only
+	TraceQueryText bool `yaml:"trace_query_text" toml:"trace_query_text"`
+}
+
+// AuthConfig configures JWT issuing and validation.
+type AuthConfig struct {
+	JWTSecret string   `yaml:"jwt_secret" toml:"jwt_secret"` // JWT_SECRET
+	TokenTTL  Duration `yaml:"token_ttl" toml:"token_ttl"`   // JWT_TOKEN_TTL
+}
+
+// LogConfig configures the structured logger.
+type LogConfig struct {
+	Level  string `yaml:"level" toml:"level"`   // LOG_LEVEL: debug, info, warn or error
+	Format string `yaml:"format" toml:"format"` // LOG_FORMAT: json or text
+}
+
+// ReadCacheConfig configures the in-process cache of article and category
+// reads. A size of zero disables it.
+type ReadCacheConfig struct {
+	Size int      `yaml:"size" toml:"size"` // READ_CACHE_SIZE, in entries
+	TTL  Duration `yaml:"ttl" toml:"ttl"`   // READ_CACHE_TTL
+}
+
+// Duration is a time.Duration written as a string such as "72h" in
+// configuration files.
+type Duration time.Duration
+
+// UnmarshalText parses a duration string for the YAML and TOML decoders.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Rate is a number of requests per period, written as "20/s", "5/m" or
+// "100/10m" in configuration files.
+type Rate struct {
+	Requests int
+	Per      time.Duration
+}
+
+// UnmarshalText parses a rate string for the YAML and TOML decoders.
+func (r *Rate) UnmarshalText(text []byte) error {
+	requests, per, ok := strings.Cut(string(text), "/")
+	n, err := strconv.Atoi(requests)
+	if !ok || err != nil {
+		return fmt.Errorf("invalid rate %q, expected requests/period such as 20/s", text)
+	}
+	var d time.Duration
+	switch per {
+	case "s":
+		d = time.Second
+	case "m":
+		d = time.Minute
+	case "h":
+		d = time.Hour
+	default:
+		if d, err = time.ParseDuration(per); err != nil {
+			return fmt.Errorf("invalid rate %q, expected requests/period such as 20/s", text)
+		}
+	}
+	*r = Rate{Requests: n, Per: d}
+	return nil
+}
+
+// PerSecond returns the average number of requests allowed per second.
+func (r Rate) PerSecond() float64 {
+	if r.Per <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Per.Seconds()
+}
+
+// minJWTSecretLength is the shortest HMAC secret accepted at startup.
+const minJWTSecretLength = 16
+
+// sslModes are the sslmode values understood by libpq and pgx.
+var sslModes = map[string]bool{
+	"disable": true, "allow": true, "prefer": true,
+	"require": true, "verify-ca": true, "verify-full": true,
+}
+
+// Default returns the configuration used when nothing else is set.
+func Default() Config {
+	return Config{
+		HTTP: HTTPConfig{
+			Port:            "8080",
+			InternalPort:    "9090",
+			ShutdownTimeout: Duration(20 * time.Second),
+			Timeouts: TimeoutsConfig{
+				Default: Duration(5 * time.Second),
+				Search:  Duration(10 * time.Second),
+				Admin:   Duration(15 * time.Second),
+			},
+			Cache: CacheConfig{
+				MaxAge:       Duration(time.Minute),
+				SharedMaxAge: Duration(5 * time.Minute),
+			},
+			RateLimit: RateLimitConfig{
+				Backend: "memory",
+				Public:  RateLimitRule{Rate: Rate{Requests: 20, Per: time.Second}, Burst: 40},
+				Search:  RateLimitRule{Rate: Rate{Requests: 2, Per: time.Second}, Burst: 10},
+				Login:   RateLimitRule{Rate: Rate{Requests: 5, Per: time.Minute}, Burst: 5},
+				Admin:   RateLimitRule{Rate: Rate{Requests: 10, Per: time.Second}, Burst: 20},
+			},
+			CORS: CORSConfig{
+				AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+				AllowedHeaders: []string{"Authorization", "Content-Type", "If-Match", "If-None-Match", "X-Request-ID"},
+				MaxAge:         Duration(10 * time.Minute),
+			},
+			Security: SecurityConfig{
+				// The API only serves JSON, which never needs to load anything
+				ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+				FrameOptions:          "DENY",
+				HSTSMaxAge:            Duration(180 * 24 * time.Hour),
+			},
+		},
+		Database:  DatabaseConfig{Port: "5432", SSLMode: "disable"},
+		Auth:      AuthConfig{TokenTTL: Duration(72 * time.Hour)},
+		Log:       LogConfig{Level: "info", Format: "json"},
+		ReadCache: ReadCacheConfig{Size: 10000, TTL: Duration(time.Minute)},
+	}
+}
+
+// Load builds the configuration from defaults, the optional file at path
+// (YAML or TOML, chosen by extension; CONFIG_FILE is used when path is
+// empty), the .env file and the environment, then validates it. All
+// problems are reported together in a single error.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	// .env is optional; it never overrides variables already set
+	_ = godotenv.Load()
+
+	var errs []error
+	errs = append(errs, applyEnv(&cfg)...)
+	errs = append(errs, cfg.Validate()...)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return &cfg, nil
+}
+
+// Validate checks required values and formats, returning one error per
+// problem found.
+func (c *Config) Validate() []error {
+	var errs []error
+	required := func(name, value string) {
+		if strings.TrimSpace(value) == "" {
+			errs = append(errs, fmt.Errorf("  %s is required", name))
+		}
+	}
+	port := func(name, value string) {
+		if value == "" {
+			return
+		}
+		if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 65535 {
+			errs = append(errs, fmt.Errorf("  %s must be a port number between 1 and 65535, got %q", name, value))
+		}
+	}
+
+	port("API_PORT", c.HTTP.Port)
+	port("INTERNAL_PORT", c.HTTP.InternalPort)
+	if c.HTTP.InternalPort != "" && c.HTTP.InternalPort == c.HTTP.Port {
+		errs = append(errs, fmt.Errorf("  INTERNAL_PORT must differ from API_PORT"))
+	}
+	if c.HTTP.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("  SHUTDOWN_TIMEOUT must be a positive duration"))
+	}
+	if c.HTTP.Timeouts.Default < 0 || c.HTTP.Timeouts.Search < 0 || c.HTTP.Timeouts.Admin < 0 {
+		errs = append(errs, fmt.Errorf("  query timeouts must not be negative"))
+	}
+	if c.HTTP.Cache.MaxAge < 0 || c.HTTP.Cache.SharedMaxAge < 0 || c.HTTP.Cache.StaleWhileRevalidate < 0 {
+		errs = append(errs, fmt.Errorf("  cache durations must not be negative"))
+	}
+	if c.HTTP.RateLimit.Backend != "memory" && c.HTTP.RateLimit.Backend != "postgres" {
+		errs = append(errs, fmt.Errorf("  RATE_LIMIT_BACKEND must be memory or postgres, got %q", c.HTTP.RateLimit.Backend))
+	}
+	for _, origin := range c.HTTP.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.HTTP.CORS.AllowCredentials {
+				errs = append(errs, fmt.Errorf("  CORS_ALLOWED_ORIGINS must list origins, not *, when CORS_ALLOW_CREDENTIALS is set"))
+			}
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+			errs = append(errs, fmt.Errorf("  CORS_ALLOWED_ORIGINS must hold origins such as https://example.com, got %q", origin))
+		}
+	}
+	if c.HTTP.CORS.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("  CORS_MAX_AGE must not be negative"))
+	}
+	switch c.HTTP.Security.FrameOptions {
+	case "", "DENY", "SAMEORIGIN":
+	default:
+		errs = append(errs, fmt.Errorf("  SECURITY_FRAME_OPTIONS must be DENY or SAMEORIGIN, got %q", c.HTTP.Security.FrameOptions))
+	}
+	if c.HTTP.Security.HSTSMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("  SECURITY_HSTS_MAX_AGE must not be negative"))
+	}
+	for _, proxy := range c.HTTP.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			errs = append(errs, fmt.Errorf("  TRUSTED_PROXIES must hold IP addresses or CIDRs, got %q", proxy))
+		}
+	}
+	for name, rule := range c.HTTP.RateLimit.rules() {
+		if rule.Rate.Requests < 0 || rule.Rate.Per < 0 {
+			errs = append(errs, fmt.Errorf("  %s must not be negative", name))
+		}
+		if rule.Rate.Requests > 0 && rule.Rate.Per == 0 {
+			errs = append(errs, fmt.Errorf("  %s must have a positive period", name))
+		}
+		if rule.Rate.Requests > 0 && rule.Burst < 1 {
+			errs = append(errs, fmt.Errorf("  %s_BURST must be at least 1", name))
+		}
+	}
+
+	required("DB_HOST", c.Database.Host)
+	required("DB_PORT", c.Database.Port)
+	port("DB_PORT", c.Database.Port)
+	required("DB_USER", c.Database.User)
+	required("DB_NAME", c.Database.Name)
+	if !sslModes[c.Database.SSLMode] {
+		errs = append(errs, fmt.Errorf("  DB_SSL_MODE must be one of disable, allow, prefer, require, verify-ca or verify-full, got %q", c.Database.SSLMode))
+	}
+
+	required("JWT_SECRET", c.Auth.JWTSecret)
+	if c.Auth.JWTSecret != "" && len(c.Auth.JWTSecret) < minJWTSecretLength {
+		errs = append(errs, fmt.Errorf("  JWT_SECRET must be at least %d characters long", minJWTSecretLength))
+	}
+	if c.Auth.TokenTTL <= 0 {
+		errs = append(errs, fmt.Errorf("  JWT_TOKEN_TTL must be a positive duration"))
+	}
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("  LOG_LEVEL must be one of debug, info, warn or error, got %q", c.Log.Level))
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		errs = append(errs, fmt.Errorf("  LOG_FORMAT must be json or text, got %q", c.Log.Format))
+	}
+
+	if c.ReadCache.Size < 0 {
+		errs = append(errs, fmt.Errorf("  READ_CACHE_SIZE must not be negative"))
+	}
+	if c.ReadCache.Size > 0 && c.ReadCache.TTL <= 0 {
+		errs = append(errs, fmt.Errorf("  READ_CACHE_TTL must be a positive duration"))
+	}
+	return errs
+}
+
+// rules returns the rate limit rules by environment variable.
+func (c *RateLimitConfig) rules() map[string]*RateLimitRule {
+	return map[string]*RateLimitRule{
+		"RATE_LIMIT_PUBLIC": &c.Public,
+		"RATE_LIMIT_SEARCH": &c.Search,
+		"RATE_LIMIT_LOGIN":  &c.Login,
+		"RATE_LIMIT_ADMIN":  &c.Admin,
+	}
+}
+
+// ConnString returns the pgx connection URL for the database.
+func (d DatabaseConfig) ConnString() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     d.Host + ":" + d.Port,
+		Path:     "/" + d.Name,
+		RawQuery: url.Values{"sslmode": {d.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("unsupported config file type %q (use .yaml, .yml or .toml)", filepath.Ext(path))
+	}
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overrides configuration values with the environment variables
+// that are set, returning an error for every value that fails to parse.
+func applyEnv(cfg *Config) []error {
+	strs := map[string]*string{
+		"API_PORT":    &cfg.HTTP.Port,
+		"DB_HOST":     &cfg.Database.Host,
+		"DB_PORT":     &cfg.Database.Port,
+		"DB_USER":     &cfg.Database.User,
+		"DB_PASSWORD": &cfg.Database.Password,
+		"DB_NAME":     &cfg.Database.Name,
+		"DB_SSL_MODE": &cfg.Database.SSLMode,
+		"JWT_SECRET":  &cfg.Auth.JWTSecret,
+		"LOG_LEVEL":   &cfg.Log.Level,
+		"LOG_FORMAT":  &cfg.Log.Format,
+
+		"INTERNAL_PORT":      &cfg.HTTP.InternalPort,
+		"RATE_LIMIT_BACKEND": &cfg.HTTP.RateLimit.Backend,
+
+		"SECURITY_CSP":           &cfg.HTTP.Security.ContentSecurityPolicy,
+		"SECURITY_FRAME_OPTIONS": &cfg.HTTP.Security.FrameOptions,
+	}
+	for name, dst := range strs {
+		if v, ok := os.LookupEnv(name); ok {
+			*dst = v
+		}
+	}
+
+	lists := map[string]*[]string{
+		"CORS_ALLOWED_ORIGINS": &cfg.HTTP.CORS.AllowedOrigins,
+		"CORS_ALLOWED_METHODS": &cfg.HTTP.CORS.AllowedMethods,
+		"CORS_ALLOWED_HEADERS": &cfg.HTTP.CORS.AllowedHeaders,
+		"TRUSTED_PROXIES":      &cfg.HTTP.TrustedProxies,
+	}
+	for name, dst := range lists {
+		if v, ok := os.LookupEnv(name); ok {
+			*dst = splitList(v)
+		}
+	}
+
+	var errs []error
+	bools := map[string]*bool{
+		"DB_AUTO_MIGRATE":        &cfg.Database.AutoMigrate,
+		"DB_TRACE_QUERY_TEXT":    &cfg.Database.TraceQueryText,
+		"CORS_ALLOW_CREDENTIALS": &cfg.HTTP.CORS.AllowCredentials,
+	}
+	for name, dst := range bools {
+		if v, ok := os.LookupEnv(name); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("  %s must be true or false, got %q", name, v))
+			} else {
+				*dst = b
+			}
+		}
+	}
+	if v, ok := os.LookupEnv("READ_CACHE_SIZE"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("  READ_CACHE_SIZE must be a number of entries, got %q", v))
+		} else {
+			cfg.ReadCache.Size = n
+		}
+	}
+	for name, rule := range cfg.HTTP.RateLimit.rules() {
+		if v, ok := os.LookupEnv(name); ok {
+			if err := rule.Rate.UnmarshalText([]byte(v)); err != nil {
+				errs = append(errs, fmt.Errorf("  %s must be a rate such as 20/s, got %q", name, v))
+			}
+		}
+		if v, ok := os.LookupEnv(name + "_BURST"); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("  %s_BURST must be a number of requests, got %q", name, v))
+			} else {
+				rule.Burst = n
+			}
+		}
+	}
+
+	durations := map[string]*Duration{
+		"SHUTDOWN_TIMEOUT":     &cfg.HTTP.ShutdownTimeout,
+		"QUERY_TIMEOUT":        &cfg.HTTP.Timeouts.Default,
+		"SEARCH_QUERY_TIMEOUT": &cfg.HTTP.Timeouts.Search,
+		"ADMIN_QUERY_TIMEOUT":  &cfg.HTTP.Timeouts.Admin,
+		"JWT_TOKEN_TTL":        &cfg.Auth.TokenTTL,
+
+		"CACHE_MAX_AGE":                &cfg.HTTP.Cache.MaxAge,
+		"CACHE_SHARED_MAX_AGE":         &cfg.HTTP.Cache.SharedMaxAge,
+		"CACHE_STALE_WHILE_REVALIDATE": &cfg.HTTP.Cache.StaleWhileRevalidate,
+		"READ_CACHE_TTL":               &cfg.ReadCache.TTL,
+		"CORS_MAX_AGE":                 &cfg.HTTP.CORS.MaxAge,
+		"SECURITY_HSTS_MAX_AGE":        &cfg.HTTP.Security.HSTSMaxAge,
+	}
+	for name, dst := range durations {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("  %s must be a duration such as 10s, got %q", name, v))
+			continue
+		}
+		*dst = Duration(d)
+	}
+	return errs
+}
+
+// splitList splits a comma-separated environment variable, dropping empty
+// items.
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
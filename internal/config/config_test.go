@@ -0,0 +1,207 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// unsetenv clears the named variables for the duration of the test, so
+// values loaded from .env do not leak into other tests.
+func unsetenv(t *testing.T, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `
+http:
+  port: "8081"
+  shutdown_timeout: 30s
+database:
+  host: file-host
+  user: file-user
+  name: file-db
+auth:
+  jwt_secret: file-secret-0123456789
+  token_ttl: 24h
+log:
+  level: warn
+`,
+		"config.toml": `
+[http]
+port = "8081"
+shutdown_timeout = "30s"
+
+[database]
+host = "file-host"
+user = "file-user"
+name = "file-db"
+
+[auth]
+jwt_secret = "file-secret-0123456789"
+token_ttl = "24h"
+
+[log]
+level = "warn"
+`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			unsetenv(t, "CONFIG_FILE", "API_PORT", "DB_HOST", "DB_USER", "DB_NAME", "JWT_TOKEN_TTL", "LOG_LEVEL", "SHUTDOWN_TIMEOUT")
+			dir := t.TempDir()
+			path := writeFile(t, dir, name, content)
+			writeFile(t, dir, ".env", "DB_HOST=dotenv-host\nDB_USER=dotenv-user\n")
+			t.Chdir(dir)
+			t.Setenv("DB_USER", "env-user")
+
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			tests := []struct {
+				name string
+				got  any
+				want any
+			}{
+				{"default", cfg.Database.Port, "5432"},
+				{"file over default", cfg.HTTP.Port, "8081"},
+				{"file duration", cfg.Auth.TokenTTL, Duration(24 * time.Hour)},
+				{"file shutdown timeout", cfg.HTTP.ShutdownTimeout, Duration(30 * time.Second)},
+				{"file level", cfg.Log.Level, "warn"},
+				{"file only", cfg.Database.Name, "file-db"},
+				{".env over file", cfg.Database.Host, "dotenv-host"},
+				{"env over .env", cfg.Database.User, "env-user"},
+			}
+			for _, tt := range tests {
+				if tt.got != tt.want {
+					t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadUnsupportedFile(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "config.json", "{}")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "unsupported config file type") {
+		t.Errorf("Load(%s) = %v, want unsupported file type", path, err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := Default()
+	cfg.HTTP.Port = "0"
+	cfg.HTTP.ShutdownTimeout = 0
+	cfg.Auth.TokenTTL = 0
+	cfg.Database.SSLMode = "sometimes"
+	cfg.Log.Format = "xml"
+
+	errs := cfg.Validate()
+	want := []string{
+		"API_PORT must be a port number",
+		"SHUTDOWN_TIMEOUT must be a positive duration",
+		"DB_HOST is required",
+		"DB_USER is required",
+		"DB_NAME is required",
+		"DB_SSL_MODE must be one of",
+		"JWT_SECRET is required",
+		"JWT_TOKEN_TTL must be a positive duration",
+		"LOG_FORMAT must be json or text",
+	}
+	if len(errs) != len(want) {
+		t.Errorf("got %d errors, want %d: %v", len(errs), len(want), errs)
+	}
+	for _, w := range want {
+		found := false
+		for _, err := range errs {
+			if strings.Contains(err.Error(), w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("missing error %q in %v", w, errs)
+		}
+	}
+}
+
+func TestRateUnmarshalText(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rate
+		wantErr bool
+	}{
+		{in: "20/s", want: Rate{Requests: 20, Per: time.Second}},
+		{in: "5/m", want: Rate{Requests: 5, Per: time.Minute}},
+		{in: "100/h", want: Rate{Requests: 100, Per: time.Hour}},
+		{in: "100/10m", want: Rate{Requests: 100, Per: 10 * time.Minute}},
+		{in: "0/s", want: Rate{Requests: 0, Per: time.Second}},
+		{in: "20", wantErr: true},
+		{in: "twenty/s", wantErr: true},
+		{in: "20/fortnight", wantErr: true},
+		{in: "20/", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got Rate
+			err := got.UnmarshalText([]byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalText(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("UnmarshalText(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRateLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		rule RateLimitRule
+		want string
+	}{
+		{"valid", RateLimitRule{Rate: Rate{Requests: 10, Per: time.Second}, Burst: 1}, ""},
+		{"disabled", RateLimitRule{}, ""},
+		{"zero period", RateLimitRule{Rate: Rate{Requests: 10}, Burst: 1}, "RATE_LIMIT_PUBLIC must have a positive period"},
+		{"negative", RateLimitRule{Rate: Rate{Requests: -1, Per: time.Second}}, "RATE_LIMIT_PUBLIC must not be negative"},
+		{"no burst", RateLimitRule{Rate: Rate{Requests: 10, Per: time.Second}}, "RATE_LIMIT_PUBLIC_BURST must be at least 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Database = DatabaseConfig{Host: "localhost", Port: "5432", User: "zysj", Name: "zysj", SSLMode: "disable"}
+			cfg.Auth.JWTSecret = "test-secret-0123456789"
+			cfg.HTTP.RateLimit.Public = tt.rule
+
+			errs := cfg.Validate()
+			if tt.want == "" {
+				if len(errs) > 0 {
+					t.Errorf("Validate() = %v, want no errors", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.want) {
+				t.Errorf("Validate() = %v, want %q", errs, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package content
+
+import "github.com/jalikey/zysj-backend/internal/models"
+
+// PrepareArticle fills in the content format, the sanitised HTML rendered
+// from the article's source content, its table of contents and the summary
+// fields used by list endpoints. It must be called before an article is
+// saved.
+func PrepareArticle(article *models.Article) error {
+	if article.ContentFormat == "" {
+		article.ContentFormat = FormatPlain
+	}
+	rendered, err := Render(article.ContentFormat, article.Content)
+	if err != nil {
+		return err
+	}
+	rendered, toc, err := AddHeadingAnchors(rendered)
+	if err != nil {
+		return err
+	}
+	text, err := PlainText(rendered)
+	if err != nil {
+		return err
+	}
+	article.ContentHTML = rendered
+	article.TOC = toc
+	article.Excerpt = Excerpt(text, ExcerptLength)
+	article.WordCount = WordCount(text)
+	article.CharCount = CharCount(text)
+	return nil
+}
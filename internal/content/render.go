@@ -0,0 +1,79 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Supported source formats for article content.
+const (
+	FormatPlain    = "plain"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// markdown converts Markdown to HTML. Raw HTML inside the source is dropped
+// by goldmark's default renderer rather than passed through; content that
+// needs markup uses the html format, which is sanitised.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+)
+
+// policy strips scripts, event handlers and other unsafe markup while keeping
+// the formatting tags that user-generated content normally needs.
+var policy = bluemonday.UGCPolicy()
+
+// IsValidFormat reports whether format is one of the supported source formats.
+func IsValidFormat(format string) bool {
+	switch format {
+	case FormatPlain, FormatMarkdown, FormatHTML:
+		return true
+	}
+	return false
+}
+
+// Render converts source in the given format to sanitised HTML.
+func Render(format, source string) (string, error) {
+	switch format {
+	case FormatPlain, "":
+		return renderPlain(source), nil
+	case FormatMarkdown:
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(source), &buf); err != nil {
+			return "", err
+		}
+		return policy.Sanitize(buf.String()), nil
+	case FormatHTML:
+		return policy.Sanitize(source), nil
+	default:
+		return "", fmt.Errorf("unsupported content format: %q", format)
+	}
+}
+
+// renderPlain escapes plain text and turns blank-line separated blocks into
+// paragraphs, keeping single line breaks inside a paragraph.
+func renderPlain(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var b strings.Builder
+	for _, para := range strings.Split(source, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		lines := strings.Split(para, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.Join(lines, "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
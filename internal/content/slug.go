@@ -0,0 +1,60 @@
+package content
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// MaxSlugLength caps generated slugs, leaving room within the varchar(255)
+// slug columns for the numeric suffix that keeps them unique.
+const MaxSlugLength = 200
+
+var pinyinArgs = pinyin.NewArgs()
+
+// Slugify turns a title or name into a URL slug: lowercase ASCII words
+// joined by hyphens. Chinese characters are transliterated to toneless
+// pinyin, one word per character, so "中医基础" becomes "zhong-yi-ji-chu".
+// Other characters separate words. The result is empty when text has
+// nothing to transliterate.
+func Slugify(text string) string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word.WriteRune(unicode.ToLower(r))
+		case unicode.Is(unicode.Han, r):
+			flush()
+			if py := pinyin.SinglePinyin(r, pinyinArgs); len(py) > 0 && py[0] != "" {
+				words = append(words, py[0])
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	slug := ""
+	for _, w := range words {
+		if slug == "" && len(w) > MaxSlugLength {
+			return w[:MaxSlugLength]
+		}
+		if len(slug)+len(w)+1 > MaxSlugLength {
+			break
+		}
+		if slug != "" {
+			slug += "-"
+		}
+		slug += w
+	}
+	return slug
+}
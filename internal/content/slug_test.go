@@ -0,0 +1,53 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"ascii", "Hello World", "hello-world"},
+		{"punctuation", "  Yin & Yang: Basics!  ", "yin-yang-basics"},
+		{"digits", "Top 10 Herbs", "top-10-herbs"},
+		{"chinese", "中医基础", "zhong-yi-ji-chu"},
+		{"mixed", "TCM 中药 Guide", "tcm-zhong-yao-guide"},
+		{"chinese punctuation", "黄帝内经：素问", "huang-di-nei-jing-su-wen"},
+		{"accents dropped", "Café", "caf"},
+		{"nothing to keep", "!!! ???", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.text); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugifyLength(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"long word cut", strings.Repeat("a", MaxSlugLength+50), MaxSlugLength},
+		{"stops before the word that overflows", strings.Repeat("abcd ", 60), 199},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Slugify(tt.text)
+			if len(got) != tt.want {
+				t.Errorf("got %d characters, want %d", len(got), tt.want)
+			}
+			if strings.HasSuffix(got, "-") {
+				t.Errorf("got trailing hyphen in %q", got)
+			}
+		})
+	}
+}
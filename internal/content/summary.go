@@ -0,0 +1,83 @@
+package content
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// ExcerptLength is the number of characters kept in an article excerpt.
+const ExcerptLength = 200
+
+// PlainText extracts the visible text from rendered HTML, collapsing runs of
+// whitespace into single spaces.
+func PlainText(rendered string) (string, error) {
+	nodes, err := parseFragment(rendered)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, n := range nodes {
+		writeText(&b, n)
+	}
+	return strings.Join(strings.Fields(b.String()), " "), nil
+}
+
+// writeText writes the text below n, separating elements with spaces so
+// that adjacent blocks and list items don't run together.
+func writeText(b *strings.Builder, n *html.Node) {
+	if n.Type == html.TextNode {
+		b.WriteString(n.Data)
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeText(b, c)
+	}
+	if n.Type == html.ElementNode {
+		b.WriteByte(' ')
+	}
+}
+
+// Excerpt returns at most n characters of text, cut on a character boundary.
+func Excerpt(text string, n int) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:n])) + "…"
+}
+
+// WordCount counts words in text. Every Han character counts as a word, as
+// is usual for Chinese text; runs of other letters and digits count as one.
+func WordCount(text string) int {
+	count := 0
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			count++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				count++
+				inWord = true
+			}
+		default:
+			inWord = false
+		}
+	}
+	return count
+}
+
+// CharCount counts the non-whitespace characters in text.
+func CharCount(text string) int {
+	count := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,161 @@
+package content
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/jalikey/zysj-backend/internal/models"
+)
+
+// headingLevels maps heading elements to their level.
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// AddHeadingAnchors gives every top-level heading in the rendered HTML a
+// stable id derived from its text and returns the updated HTML together
+// with the table of contents built from those headings.
+func AddHeadingAnchors(rendered string) (string, []models.TOCEntry, error) {
+	nodes, err := parseFragment(rendered)
+	if err != nil {
+		return "", nil, err
+	}
+
+	toc := []models.TOCEntry{}
+	used := make(map[string]bool)
+	for _, n := range nodes {
+		level, ok := headingLevel(n)
+		if !ok {
+			continue
+		}
+		text := strings.TrimSpace(nodeText(n))
+		anchor := uniqueAnchor(Anchor(text), used)
+		setAttr(n, "id", anchor)
+		toc = append(toc, models.TOCEntry{Level: level, Text: text, Anchor: anchor})
+	}
+
+	out, err := renderNodes(nodes)
+	if err != nil {
+		return "", nil, err
+	}
+	return out, toc, nil
+}
+
+// Section returns the HTML of the section that starts at the heading with
+// the given anchor and runs until the next heading of the same or a higher
+// level. The boolean is false when no heading has that anchor.
+func Section(rendered, anchor string) (string, bool, error) {
+	nodes, err := parseFragment(rendered)
+	if err != nil {
+		return "", false, err
+	}
+
+	start, startLevel := -1, 0
+	for i, n := range nodes {
+		level, ok := headingLevel(n)
+		if !ok {
+			continue
+		}
+		if start >= 0 && level <= startLevel {
+			out, err := renderNodes(nodes[start:i])
+			return out, true, err
+		}
+		if start < 0 && attr(n, "id") == anchor {
+			start, startLevel = i, level
+		}
+	}
+	if start < 0 {
+		return "", false, nil
+	}
+	out, err := renderNodes(nodes[start:])
+	return out, true, err
+}
+
+// Anchor turns heading text into an anchor id. Letters and digits of any
+// script are kept so Chinese headings stay readable; everything else
+// collapses into single hyphens.
+func Anchor(text string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			hyphen = false
+		} else if !hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+	anchor := strings.TrimSuffix(b.String(), "-")
+	if anchor == "" {
+		anchor = "section"
+	}
+	return anchor
+}
+
+// uniqueAnchor appends a numeric suffix when the anchor was already used.
+func uniqueAnchor(anchor string, used map[string]bool) string {
+	candidate := anchor
+	for i := 1; used[candidate]; i++ {
+		candidate = anchor + "-" + strconv.Itoa(i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+func parseFragment(rendered string) ([]*html.Node, error) {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	return html.ParseFragment(strings.NewReader(rendered), body)
+}
+
+func renderNodes(nodes []*html.Node) (string, error) {
+	var b strings.Builder
+	for _, n := range nodes {
+		if err := html.Render(&b, n); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+func headingLevel(n *html.Node) (int, bool) {
+	if n.Type != html.ElementNode {
+		return 0, false
+	}
+	level, ok := headingLevels[n.DataAtom]
+	return level, ok
+}
+
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(nodeText(c))
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(n *html.Node, key, val string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
@@ -0,0 +1,84 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnchor(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Introduction", "introduction"},
+		{"Getting Started", "getting-started"},
+		{"  What's new?  ", "what-s-new"},
+		{"Step 1 -- Install", "step-1-install"},
+		{"中医基础理论", "中医基础理论"},
+		{"阴阳 与 五行", "阴阳-与-五行"},
+		{"!!!", "section"},
+		{"", "section"},
+	}
+	for _, tt := range tests {
+		if got := Anchor(tt.text); got != tt.want {
+			t.Errorf("Anchor(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSection(t *testing.T) {
+	const doc = `<p>Intro</p>` +
+		`<h2 id="a">A</h2><p>a1</p>` +
+		`<h3 id="a-1">A.1</h3><p>a1.1</p>` +
+		`<h2 id="b">B</h2><p>b1</p>`
+
+	tests := []struct {
+		anchor    string
+		want      string
+		wantFound bool
+	}{
+		{"a", `<h2 id="a">A</h2><p>a1</p><h3 id="a-1">A.1</h3><p>a1.1</p>`, true},
+		{"a-1", `<h3 id="a-1">A.1</h3><p>a1.1</p>`, true},
+		{"b", `<h2 id="b">B</h2><p>b1</p>`, true},
+		{"missing", "", false},
+	}
+	for _, tt := range tests {
+		got, found, err := Section(doc, tt.anchor)
+		if err != nil {
+			t.Fatalf("Section(%q): %v", tt.anchor, err)
+		}
+		if found != tt.wantFound || got != tt.want {
+			t.Errorf("Section(%q) = %q, %v; want %q, %v", tt.anchor, got, found, tt.want, tt.wantFound)
+		}
+	}
+}
+
+func TestAddHeadingAnchorsDeduplicates(t *testing.T) {
+	out, toc, err := AddHeadingAnchors(`<h2>Notes</h2><p>x</p><h2>Notes</h2>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<h2 id="notes">Notes</h2><p>x</p><h2 id="notes-1">Notes</h2>`; out != want {
+		t.Errorf("html = %q, want %q", out, want)
+	}
+	if len(toc) != 2 || toc[0].Anchor != "notes" || toc[1].Anchor != "notes-1" || toc[1].Level != 2 {
+		t.Errorf("toc = %+v", toc)
+	}
+}
+
+func TestRenderMarkdownDropsRawHTML(t *testing.T) {
+	for _, source := range []string{
+		"# Title\n\n<script>alert(1)</script>\n",
+		"text <img src=x onerror=alert(1)> more\n",
+	} {
+		got, err := Render(FormatMarkdown, source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, bad := range []string{"<script", "onerror", "alert(1)"} {
+			if strings.Contains(got, bad) {
+				t.Errorf("Render(%q) = %q, contains %q", source, got, bad)
+			}
+		}
+	}
+}
@@ -2,48 +2,50 @@ package database
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jalikey/zysj-backend/internal/config"
+	"github.com/jalikey/zysj-backend/internal/tracing"
 )
 
 // DB a connection pool for the database
 var DB *pgxpool.Pool
 
 // ConnectDB establishes a connection to the PostgreSQL database
-func ConnectDB() {
-	var err error
-	
-	// Construct the database connection string from environment variables
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_SSL_MODE"),
-	)
+func ConnectDB(cfg config.DatabaseConfig) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.ConnString())
+	if err != nil {
+		slog.Error("Invalid database configuration", "error", err)
+		os.Exit(1)
+	}
+	// Record a span for every query; without a tracer provider it is a no-op
+	poolConfig.ConnConfig.Tracer = tracing.QueryTracer{QueryText: cfg.TraceQueryText}
 
 	// Create a new connection pool
-	DB, err = pgxpool.New(context.Background(), connStr)
+	DB, err = pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
-		log.Fatalf("Unable to connect to database: %v\n", err)
+		slog.Error("Unable to connect to database", "error", err)
+		os.Exit(1)
 	}
 
 	// Ping the database to verify the connection
 	if err := DB.Ping(context.Background()); err != nil {
-		log.Fatalf("Failed to ping database: %v\n", err)
+		slog.Error("Failed to ping database", "error", err)
+		os.Exit(1)
 	}
 
-	fmt.Println("Successfully connected to the database!")
+	slog.Info("Successfully connected to the database")
 }
 
-// CloseDB closes the database connection pool
+// CloseDB closes the database connection pool. It is safe to call more
+// than once.
 func CloseDB() {
 	if DB != nil {
 		DB.Close()
-		fmt.Println("Database connection closed.")
+		DB = nil
+		slog.Info("Database connection closed")
 	}
 }
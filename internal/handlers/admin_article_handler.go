@@ -1,95 +1,210 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jalikey/zysj-backend/internal/cache"
+	"github.com/jalikey/zysj-backend/internal/content"
+	"github.com/jalikey/zysj-backend/internal/metrics"
 	"github.com/jalikey/zysj-backend/internal/models"
-	"github.com/jalikey/zysj-backend/internal/repository"
 )
 
+// ArticlePayload is the body of article create and update requests. Length
+// limits match the varchar(255) columns; a zero category_id leaves the
+// article uncategorised. An empty slug is generated from the title on
+// creation and left unchanged on update.
 type ArticlePayload struct {
-	Title      string `json:"title" binding:"required"`
-	Content    string `json:"content" binding:"required"`
-	CategoryID int64  `json:"category_id"`
-	Author     string `json:"author"`
-	Source     string `json:"source"`
+	Title         string `json:"title" binding:"required,notblank,max=255"`
+	Slug          string `json:"slug" binding:"omitempty,max=255,slug"`
+	Content       string `json:"content" binding:"required,notblank"`
+	ContentFormat string `json:"content_format" binding:"omitempty,oneof=plain markdown html"`
+	CategoryID    int64  `json:"category_id" binding:"gte=0"`
+	Author        string `json:"author" binding:"max=255"`
+	Source        string `json:"source" binding:"max=255"`
 }
 
 // CreateArticle handles POST requests to create a new article.
-func CreateArticle(c *gin.Context) {
+func (h *Handler) CreateArticle(c *gin.Context) {
 	var payload ArticlePayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		fail(c, invalidPayload(err))
 		return
 	}
 
 	article := models.Article{
-		Title:   payload.Title,
-		Content: payload.Content,
-		Author:  payload.Author,
-		Source:  payload.Source,
+		Title:         payload.Title,
+		Content:       payload.Content,
+		ContentFormat: payload.ContentFormat,
+		Author:        payload.Author,
+		Source:        payload.Source,
 	}
 	if payload.CategoryID > 0 {
 		article.CategoryID = models.NullInt64{Int64: payload.CategoryID, Valid: true}
 	}
-
-	newID, err := repository.CreateArticle(article)
+	if err := h.checkCategoryExists(c.Request.Context(), "category_id", payload.CategoryID); err != nil {
+		fail(c, err)
+		return
+	}
+	if err := content.PrepareArticle(&article); err != nil {
+		fail(c, &APIError{Status: http.StatusUnprocessableEntity, Code: "invalid_content", Detail: "The article content could not be rendered", Err: err})
+		return
+	}
+	newID, err := createWithSlug(c.Request.Context(), payload.Slug, payload.Title, h.Articles.UniqueArticleSlug, func(slug string) (int64, error) {
+		article.Slug = slug
+		return h.Articles.CreateArticle(c.Request.Context(), article)
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create article"})
+		fail(c, err)
 		return
 	}
+	metrics.ArticleChanged("created")
 
-	createdArticle, _ := repository.GetArticleByID(newID)
+	createdArticle, _ := h.Articles.GetArticleByID(c.Request.Context(), newID)
+	setETag(c, createdArticle.Version)
 	c.JSON(http.StatusCreated, createdArticle)
 }
 
-// UpdateArticle handles PUT requests to update an article.
-func UpdateArticle(c *gin.Context) {
+// UpdateArticle handles PUT requests to update an article. Every field is
+// replaced, so an omitted category_id leaves the article uncategorised; use
+// PATCH to change only some fields. The If-Match header is required, and
+// the update only succeeds if the article is still at that version; without
+// it the request fails with 428.
+func (h *Handler) UpdateArticle(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		fail(c, badRequest("invalid_id", "Invalid article ID"))
 		return
 	}
 
 	var payload ArticlePayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		fail(c, invalidPayload(err))
+		return
+	}
+
+	if c.GetHeader("If-Match") == "" {
+		fail(c, preconditionRequired("article"))
+		return
+	}
+	current, err := h.Articles.GetArticleByID(cache.Bypass(c.Request.Context()), id)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	if !ifMatch(c, current.Version) {
+		fail(c, preconditionFailed("article"))
+		return
+	}
+
+	article, err := h.saveArticle(c.Request.Context(), id, current.Version, payload)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	setETag(c, article.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Article updated successfully"})
+}
+
+// PatchArticle handles PATCH requests to update some fields of an article.
+// The body is a JSON Merge Patch against the fields of ArticlePayload. The
+// update is based on the version read here, so a concurrent write makes it
+// fail with 412 instead of being overwritten, and an If-Match header can
+// require a specific version.
+func (h *Handler) PatchArticle(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		fail(c, badRequest("invalid_id", "Invalid article ID"))
+		return
+	}
+
+	current, err := h.Articles.GetArticleByID(cache.Bypass(c.Request.Context()), id)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	if !ifMatch(c, current.Version) {
+		fail(c, preconditionFailed("article"))
+		return
+	}
+
+	var payload ArticlePayload
+	if err := bindMergePatch(c, articlePayloadOf(current), &payload); err != nil {
+		fail(c, err)
+		return
+	}
+
+	article, err := h.saveArticle(c.Request.Context(), id, current.Version, payload)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
+	setETag(c, article.Version)
+	c.JSON(http.StatusOK, article)
+}
+
+// articlePayloadOf returns the payload that would recreate an article.
+func articlePayloadOf(article models.Article) ArticlePayload {
+	return ArticlePayload{
+		Title:         article.Title,
+		Slug:          article.Slug,
+		Content:       article.Content,
+		ContentFormat: article.ContentFormat,
+		CategoryID:    article.CategoryID.Int64,
+		Author:        article.Author,
+		Source:        article.Source,
+	}
+}
+
+// saveArticle stores payload as the new state of article id and returns the
+// updated article. A non-zero version makes the update conditional on the
+// article still being at that version.
+func (h *Handler) saveArticle(ctx context.Context, id, version int64, payload ArticlePayload) (models.Article, error) {
 	article := models.Article{
-		ID:      id,
-		Title:   payload.Title,
-		Content: payload.Content,
-		Author:  payload.Author,
-		Source:  payload.Source,
+		ID:            id,
+		Title:         payload.Title,
+		Slug:          payload.Slug,
+		Content:       payload.Content,
+		ContentFormat: payload.ContentFormat,
+		Author:        payload.Author,
+		Source:        payload.Source,
+		Version:       version,
 	}
 	if payload.CategoryID > 0 {
 		article.CategoryID = models.NullInt64{Int64: payload.CategoryID, Valid: true}
 	}
+	if err := h.checkCategoryExists(ctx, "category_id", payload.CategoryID); err != nil {
+		return models.Article{}, err
+	}
+	if err := content.PrepareArticle(&article); err != nil {
+		return models.Article{}, &APIError{Status: http.StatusUnprocessableEntity, Code: "invalid_content", Detail: "The article content could not be rendered", Err: err}
+	}
 
-	if err := repository.UpdateArticle(article); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update article"})
-		return
+	if err := h.Articles.UpdateArticle(ctx, article); err != nil {
+		return models.Article{}, err
 	}
+	metrics.ArticleChanged("updated")
 
-	c.JSON(http.StatusOK, gin.H{"message": "Article updated successfully"})
+	return h.Articles.GetArticleByID(ctx, id)
 }
 
 // DeleteArticle handles DELETE requests to remove an article.
-func DeleteArticle(c *gin.Context) {
+func (h *Handler) DeleteArticle(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		fail(c, badRequest("invalid_id", "Invalid article ID"))
 		return
 	}
 
-	if err := repository.DeleteArticle(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete article"})
+	if err := h.Articles.DeleteArticle(c.Request.Context(), id); err != nil {
+		fail(c, err)
 		return
 	}
+	metrics.ArticleChanged("deleted")
 
 	c.JSON(http.StatusOK, gin.H{"message": "Article deleted successfully"})
 }
\ No newline at end of file
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jalikey/zysj-backend/internal/cache"
+	"github.com/jalikey/zysj-backend/internal/models"
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+func TestCreateArticle(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantSlug   string
+	}{
+		{"generated slug", `{"title":"Hello World","content":"text"}`, http.StatusCreated, "", "hello-world"},
+		{"given slug", `{"title":"Hello","slug":"custom","content":"text"}`, http.StatusCreated, "", "custom"},
+		{"markdown", `{"title":"Md","content":"# Heading","content_format":"markdown"}`, http.StatusCreated, "", "md"},
+		{"missing title", `{"content":"text"}`, http.StatusUnprocessableEntity, "validation_failed", ""},
+		{"blank content", `{"title":"x","content":"   "}`, http.StatusUnprocessableEntity, "validation_failed", ""},
+		{"bad slug", `{"title":"x","slug":"Not A Slug","content":"text"}`, http.StatusUnprocessableEntity, "validation_failed", ""},
+		{"bad format", `{"title":"x","content":"text","content_format":"rtf"}`, http.StatusUnprocessableEntity, "validation_failed", ""},
+		{"unknown category", `{"title":"x","content":"text","category_id":99}`, http.StatusUnprocessableEntity, "validation_failed", ""},
+		{"wrong type", `{"title":1,"content":"text"}`, http.StatusBadRequest, "invalid_type", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := newTestRouter()
+			w := serve(router, http.MethodPost, "/api/v1/admin/articles", tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantCode != "" {
+				if code := problemCode(t, w); code != tt.wantCode {
+					t.Errorf("got code %q, want %q", code, tt.wantCode)
+				}
+				return
+			}
+			var article models.Article
+			decode(t, w, &article)
+			if article.Slug != tt.wantSlug {
+				t.Errorf("got slug %q, want %q", article.Slug, tt.wantSlug)
+			}
+			if etag := w.Header().Get("ETag"); etag != versionETag(article.Version) {
+				t.Errorf("got ETag %q for version %d", etag, article.Version)
+			}
+		})
+	}
+}
+
+func TestCreateArticleDeduplicatesSlug(t *testing.T) {
+	router, _ := newTestRouter()
+	for i, want := range []string{"same", "same-2", "same-3"} {
+		w := serve(router, http.MethodPost, "/api/v1/admin/articles", `{"title":"Same","content":"text"}`)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create %d: got status %d: %s", i, w.Code, w.Body)
+		}
+		var article models.Article
+		decode(t, w, &article)
+		if article.Slug != want {
+			t.Errorf("create %d: got slug %q, want %q", i, article.Slug, want)
+		}
+	}
+}
+
+func TestUpdateArticle(t *testing.T) {
+	router, _ := newTestRouter()
+	w := serve(router, http.MethodPost, "/api/v1/admin/articles", `{"title":"Old","content":"text"}`)
+	var created models.Article
+	decode(t, w, &created)
+	target := fmt.Sprintf("/api/v1/admin/articles/%d", created.ID)
+	body := `{"title":"New","content":"changed"}`
+
+	tests := []struct {
+		name       string
+		target     string
+		ifMatch    string
+		wantStatus int
+	}{
+		{"missing If-Match", target, "", http.StatusPreconditionRequired},
+		{"stale version", target, `"0"`, http.StatusPreconditionFailed},
+		{"weak tag", target, `W/"1"`, http.StatusPreconditionFailed},
+		{"current version", target, `"1"`, http.StatusOK},
+		{"replayed version", target, `"1"`, http.StatusPreconditionFailed},
+		{"any version", target, `*`, http.StatusOK},
+		{"unknown article", "/api/v1/admin/articles/999", `*`, http.StatusNotFound},
+		{"invalid id", "/api/v1/admin/articles/abc", `*`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPut, tt.target, body, "If-Match", tt.ifMatch)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+
+	w = serve(router, http.MethodGet, fmt.Sprintf("/api/v1/articles/%d", created.ID), "")
+	var updated models.Article
+	decode(t, w, &updated)
+	if updated.Title != "New" || updated.Slug != created.Slug || updated.Version != 3 {
+		t.Errorf("got %q, slug %q, version %d after updates", updated.Title, updated.Slug, updated.Version)
+	}
+}
+
+func TestPatchArticle(t *testing.T) {
+	router, _ := newTestRouter()
+	w := serve(router, http.MethodPost, "/api/v1/admin/articles", `{"title":"Title","content":"text","author":"Li"}`)
+	var created models.Article
+	decode(t, w, &created)
+	target := fmt.Sprintf("/api/v1/admin/articles/%d", created.ID)
+
+	w = serve(router, http.MethodPatch, target, `{"source":"book","author":null}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body)
+	}
+	var patched models.Article
+	decode(t, w, &patched)
+	if patched.Title != "Title" || patched.Source != "book" || patched.Author != "" {
+		t.Errorf("got title %q, source %q, author %q", patched.Title, patched.Source, patched.Author)
+	}
+
+	w = serve(router, http.MethodPatch, target, `{"title":""}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("blanking the title: got status %d, want 422", w.Code)
+	}
+	w = serve(router, http.MethodPatch, target, `{"title":"x"}`, "If-Match", versionETag(created.Version))
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("patching an old version: got status %d, want 412", w.Code)
+	}
+}
+
+func TestDeleteArticle(t *testing.T) {
+	router, _ := newTestRouter()
+	w := serve(router, http.MethodPost, "/api/v1/admin/articles", `{"title":"Gone","content":"text"}`)
+	var created models.Article
+	decode(t, w, &created)
+	target := fmt.Sprintf("/api/v1/admin/articles/%d", created.ID)
+
+	if w := serve(router, http.MethodDelete, target, ""); w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body)
+	}
+	w = serve(router, http.MethodDelete, target, "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("deleting twice: got status %d, want 404", w.Code)
+	}
+	if code := problemCode(t, w); code != "article_not_found" {
+		t.Errorf("got code %q, want article_not_found", code)
+	}
+}
+
+// TestWriteBehindStaleCache checks that writes compare If-Match with the
+// stored version rather than a cached copy that another instance's write
+// has made stale.
+func TestWriteBehindStaleCache(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		entity string
+		body   string
+	}{
+		{"put article", http.MethodPut, "articles", `{"title":"New","content":"changed"}`},
+		{"patch article", http.MethodPatch, "articles", `{"title":"New"}`},
+		{"put category", http.MethodPut, "categories", `{"name":"New"}`},
+		{"patch category", http.MethodPatch, "categories", `{"name":"New"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := repository.NewMemoryStore()
+			cached := cache.New(cache.NewLRU(100), time.Minute, store, store)
+			router := testRoutes(New(cached, cached, store, store))
+			ctx := context.Background()
+
+			var id, version int64
+			if tt.entity == "articles" {
+				id, _ = store.CreateArticle(ctx, models.Article{Title: "Old", Slug: "old", Content: "text", ContentFormat: "plain"})
+				_, _ = cached.GetArticleByID(ctx, id)
+				// Another instance updates the article behind the cache
+				_ = store.UpdateArticle(ctx, models.Article{ID: id, Title: "Other", Slug: "old", Content: "text", ContentFormat: "plain"})
+				article, _ := store.GetArticleByID(ctx, id)
+				version = article.Version
+			} else {
+				id, _ = store.CreateCategory(ctx, models.Category{Name: "Old", Slug: "old"})
+				_, _ = cached.GetCategoryByID(ctx, id)
+				_ = store.UpdateCategory(ctx, models.Category{ID: id, Name: "Other", Slug: "old"})
+				category, _ := store.GetCategoryByID(ctx, id)
+				version = category.Version
+			}
+
+			target := fmt.Sprintf("/api/v1/admin/%s/%d", tt.entity, id)
+			w := serve(router, tt.method, target, tt.body, "If-Match", versionETag(version))
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want 200: %s", w.Code, w.Body)
+			}
+			if etag := w.Header().Get("ETag"); etag != versionETag(version+1) {
+				t.Errorf("got ETag %s, want %s", etag, versionETag(version+1))
+			}
+		})
+	}
+}
@@ -1,26 +1,30 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jalikey/zysj-backend/internal/cache"
 	"github.com/jalikey/zysj-backend/internal/models"
-	"github.com/jalikey/zysj-backend/internal/repository"
 )
 
+// CategoryPayload is the body of category create and update requests. A
+// zero parent_id makes the category a top-level one. An empty slug is
+// generated from the name on creation and left unchanged on update.
 type CategoryPayload struct {
-	Name        string `json:"name" binding:"required"`
-	Slug        string `json:"slug" binding:"required"`
+	Name        string `json:"name" binding:"required,notblank,max=255"`
+	Slug        string `json:"slug" binding:"omitempty,max=255,slug"`
 	Description string `json:"description"`
-	ParentID    int64  `json:"parent_id"`
+	ParentID    int64  `json:"parent_id" binding:"gte=0"`
 }
 
 // CreateCategory handles POST requests to create a category.
-func CreateCategory(c *gin.Context) {
+func (h *Handler) CreateCategory(c *gin.Context) {
 	var payload CategoryPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		fail(c, invalidPayload(err))
 		return
 	}
 
@@ -33,57 +37,145 @@ func CreateCategory(c *gin.Context) {
 		category.ParentID = models.NullInt64{Int64: payload.ParentID, Valid: true}
 	}
 
-	_, err := repository.CreateCategory(category)
+	if err := h.checkCategoryParent(c.Request.Context(), 0, payload.ParentID); err != nil {
+		fail(c, err)
+		return
+	}
+	_, err := createWithSlug(c.Request.Context(), payload.Slug, payload.Name, h.Categories.UniqueCategorySlug, func(slug string) (int64, error) {
+		category.Slug = slug
+		return h.Categories.CreateCategory(c.Request.Context(), category)
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
+		fail(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Category created successfully"})
 }
 
-// UpdateCategory handles PUT requests to update a category.
-func UpdateCategory(c *gin.Context) {
+// UpdateCategory handles PUT requests to update a category. Every field is
+// replaced; use PATCH to change only some fields. The If-Match header is
+// required, and the update only succeeds if the category is still at that
+// version; without it the request fails with 428.
+func (h *Handler) UpdateCategory(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		fail(c, badRequest("invalid_id", "Invalid category ID"))
 		return
 	}
 	
 	var payload CategoryPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		fail(c, invalidPayload(err))
+		return
+	}
+
+	if c.GetHeader("If-Match") == "" {
+		fail(c, preconditionRequired("category"))
+		return
+	}
+	current, err := h.Categories.GetCategoryByID(cache.Bypass(c.Request.Context()), id)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	if !ifMatch(c, current.Version) {
+		fail(c, preconditionFailed("category"))
+		return
+	}
+
+	category, err := h.saveCategory(c.Request.Context(), id, current.Version, payload)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	setETag(c, category.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Category updated successfully"})
+}
+
+// PatchCategory handles PATCH requests to update some fields of a category.
+// The body is a JSON Merge Patch against the fields of CategoryPayload; as
+// with PatchArticle, concurrent writes and If-Match mismatches fail with 412.
+func (h *Handler) PatchCategory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		fail(c, badRequest("invalid_id", "Invalid category ID"))
+		return
+	}
+
+	current, err := h.Categories.GetCategoryByID(cache.Bypass(c.Request.Context()), id)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	if !ifMatch(c, current.Version) {
+		fail(c, preconditionFailed("category"))
+		return
+	}
+
+	var payload CategoryPayload
+	if err := bindMergePatch(c, categoryPayloadOf(current), &payload); err != nil {
+		fail(c, err)
 		return
 	}
 
+	category, err := h.saveCategory(c.Request.Context(), id, current.Version, payload)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	setETag(c, category.Version)
+	c.JSON(http.StatusOK, category)
+}
+
+// categoryPayloadOf returns the payload that would recreate a category.
+func categoryPayloadOf(category models.Category) CategoryPayload {
+	return CategoryPayload{
+		Name:        category.Name,
+		Slug:        category.Slug,
+		Description: category.Description,
+		ParentID:    category.ParentID.Int64,
+	}
+}
+
+// saveCategory stores payload as the new state of category id and returns
+// the updated category. A non-zero version makes the update conditional on
+// the category still being at that version.
+func (h *Handler) saveCategory(ctx context.Context, id, version int64, payload CategoryPayload) (models.Category, error) {
 	category := models.Category{
 		ID:          id,
 		Name:        payload.Name,
 		Slug:        payload.Slug,
 		Description: payload.Description,
+		Version:     version,
 	}
 	if payload.ParentID > 0 {
 		category.ParentID = models.NullInt64{Int64: payload.ParentID, Valid: true}
 	}
 
-	if err := repository.UpdateCategory(category); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
-		return
+	if err := h.checkCategoryParent(ctx, id, payload.ParentID); err != nil {
+		return models.Category{}, err
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Category updated successfully"})
+	if err := h.Categories.UpdateCategory(ctx, category); err != nil {
+		return models.Category{}, err
+	}
+
+	return h.Categories.GetCategoryByID(ctx, id)
 }
 
 // DeleteCategory handles DELETE requests to remove a category.
-func DeleteCategory(c *gin.Context) {
+func (h *Handler) DeleteCategory(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		fail(c, badRequest("invalid_id", "Invalid category ID"))
 		return
 	}
 
-	if err := repository.DeleteCategory(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+	if err := h.Categories.DeleteCategory(c.Request.Context(), id); err != nil {
+		fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
@@ -91,22 +183,19 @@ func DeleteCategory(c *gin.Context) {
 // ... (之前的 CUD handlers) ...
 
 // GetCategoryByID handles GET request for a single category by ID.
-func GetCategoryByID(c *gin.Context) {
+func (h *Handler) GetCategoryByID(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		fail(c, badRequest("invalid_id", "Invalid category ID"))
 		return
 	}
 
-	category, err := repository.GetCategoryByID(id)
+	category, err := h.Categories.GetCategoryByID(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "no rows in result set" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve category"})
+		fail(c, err)
 		return
 	}
 
+	setETag(c, category.Version)
 	c.JSON(http.StatusOK, category)
 }
\ No newline at end of file
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jalikey/zysj-backend/internal/auth"
+	"github.com/jalikey/zysj-backend/internal/models"
+)
+
+// APIKeyPayload describes a new API key.
+type APIKeyPayload struct {
+	Name      string     `json:"name" binding:"required,notblank,max=255"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,oneof=read articles:write categories:write"`
+	ExpiresAt *time.Time `json:"expires_at"` // Never expires when omitted
+}
+
+// GetAPIKeys handles listing every API key, including revoked and expired
+// ones. Secrets are never returned.
+func (h *Handler) GetAPIKeys(c *gin.Context) {
+	keys, err := h.APIKeys.GetAllAPIKeys(c.Request.Context())
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey handles creating an API key for the logged in user. The
+// response is the only time the secret is shown.
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var payload APIKeyPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		fail(c, invalidPayload(err))
+		return
+	}
+	if payload.ExpiresAt != nil && !payload.ExpiresAt.After(time.Now()) {
+		fail(c, invalidField("expires_at", "past", "must be in the future"))
+		return
+	}
+
+	secret, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	key := models.APIKey{
+		Name:      payload.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    payload.Scopes,
+		CreatedBy: c.GetString("username"),
+		ExpiresAt: payload.ExpiresAt,
+	}
+	if _, err := h.APIKeys.CreateAPIKey(c.Request.Context(), key); err != nil {
+		fail(c, err)
+		return
+	}
+	if key, err = h.APIKeys.GetAPIKeyByHash(c.Request.Context(), hash); err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"api_key": key, "key": secret})
+}
+
+// RevokeAPIKey handles revoking an API key. Revoked keys are rejected
+// straight away but stay listed.
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		fail(c, badRequest("invalid_id", "Invalid API key ID"))
+		return
+	}
+
+	if err := h.APIKeys.RevokeAPIKey(c.Request.Context(), id); err != nil {
+		fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
@@ -4,8 +4,11 @@ import (
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jalikey/zysj-backend/internal/content"
 	"github.com/jalikey/zysj-backend/internal/models"
 	"github.com/jalikey/zysj-backend/internal/repository"
 )
@@ -27,74 +30,281 @@ func getPaginationParams(c *gin.Context) (page, limit, offset int) {
 	offset = (page - 1) * limit
 	return
 }
+
+// getArticleFields parses the comma-separated "fields" query parameter used
+// by the article list endpoints. An empty result selects the default summary
+// projection.
+func getArticleFields(c *gin.Context) ([]string, error) {
+	raw := c.Query("fields")
+	if raw == "" {
+		return nil, nil
+	}
+	var fields []string
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !repository.IsArticleListField(f) {
+			return nil, badRequest("invalid_fields", "Unknown field: "+f)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// getCountMode parses the "count" query parameter: "exact" (default),
+// "estimate" for planner estimates on huge result sets, or "none" to skip
+// totals and rely on hasMore.
+func getCountMode(c *gin.Context) (repository.CountMode, error) {
+	switch c.DefaultQuery("count", "exact") {
+	case "exact":
+		return repository.CountExact, nil
+	case "estimate":
+		return repository.CountEstimate, nil
+	case "none":
+		return repository.CountNone, nil
+	}
+	return 0, badRequest("invalid_count", "count must be one of exact, estimate or none")
+}
+
+// newPagination builds the pagination metadata for an offset-paginated page.
+func newPagination(page, limit int, count repository.CountMode, info repository.PageInfo) models.Pagination {
+	pagination := models.Pagination{
+		CurrentPage:     page,
+		PageSize:        limit,
+		TotalsEstimated: info.Estimated,
+		HasMore:         info.HasMore,
+	}
+	if count != repository.CountNone {
+		totalPages := int(math.Ceil(float64(info.Total) / float64(limit)))
+		pagination.TotalItems = &info.Total
+		pagination.TotalPages = &totalPages
+	}
+	return pagination
+}
+
+// getCursorParam reports whether the request uses keyset pagination, which
+// is selected by passing a "cursor" query parameter (empty for the first
+// page). Requests without it keep using page/limit.
+func getCursorParam(c *gin.Context) (cursor string, ok bool) {
+	return c.GetQuery("cursor")
+}
+
 // GetArticles handles the GET request for retrieving all articles.
-func GetArticles(c *gin.Context) {
+func (h *Handler) GetArticles(c *gin.Context) {
 	page, limit, offset := getPaginationParams(c)
+	fields, err := getArticleFields(c)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	count, err := getCountMode(c)
+	if err != nil {
+		fail(c, err)
+		return
+	}
 
-	articles, totalItems, err := repository.GetAllArticles(limit, offset)
+	if cursor, ok := getCursorParam(c); ok {
+		articles, next, err := h.Articles.GetArticlesAfter(c.Request.Context(), fields, cursor, limit)
+		if err != nil {
+			fail(c, err)
+			return
+		}
+		writeCacheable(c, models.CursorPaginatedResponse{
+			Data:       articles,
+			Pagination: models.CursorPagination{PageSize: limit, NextCursor: next, HasMore: next != ""},
+		}, time.Time{})
+		return
+	}
+
+	articles, info, err := h.Articles.GetAllArticles(c.Request.Context(), fields, limit, offset, count)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve articles"})
+		fail(c, err)
 		return
 	}
 
 	response := models.PaginatedResponse{
-		Data: articles,
-		Pagination: models.Pagination{
-			CurrentPage: page,
-			PageSize:    limit,
-			TotalItems:  totalItems,
-			TotalPages:  int(math.Ceil(float64(totalItems) / float64(limit))),
-		},
+		Data:       articles,
+		Pagination: newPagination(page, limit, count, info),
 	}
 
-	c.JSON(http.StatusOK, response)
+	writeCacheable(c, response, time.Time{})
 }
-func GetArticleByID(c *gin.Context) {
+
+// GetArticleByID handles the GET request for a single article.
+// The optional "format" query parameter selects what is returned in the
+// content field: "raw" (default) returns the stored source, "html" returns
+// the sanitised HTML rendered from it.
+func (h *Handler) GetArticleByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		fail(c, badRequest("invalid_id", "Invalid article ID"))
+		return
+	}
+
+	format, err := getContentFormat(c)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	article, err := h.Articles.GetArticleByID(c.Request.Context(), id)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	writeArticle(c, article, format)
+}
+
+// GetArticleBySlug handles the GET request for a single article addressed
+// by its slug. It accepts the same "format" parameter as GetArticleByID.
+// Slugs the article used before being renamed redirect permanently to its
+// current one.
+func (h *Handler) GetArticleBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	format, err := getContentFormat(c)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	article, err := h.Articles.GetArticleBySlug(c.Request.Context(), slug)
+	if err != nil {
+		if err := slugRedirect(c, slug, err, h.Articles.ArticleSlugRedirect); err != nil {
+			fail(c, err)
+		}
+		return
+	}
+
+	writeArticle(c, article, format)
+}
+
+// getContentFormat parses the "format" query parameter of the single
+// article endpoints.
+func getContentFormat(c *gin.Context) (string, error) {
+	format := c.DefaultQuery("format", "raw")
+	if format != "raw" && format != "html" {
+		return "", badRequest("invalid_format", "format must be either raw or html")
+	}
+	return format, nil
+}
+
+// writeArticle sends the article with its content in the given format, its
+// version as the ETag and its modification time as Last-Modified.
+func writeArticle(c *gin.Context, article models.Article, format string) {
+	if format == "html" {
+		// Articles saved before content rendering existed have no stored HTML yet
+		if article.ContentHTML == "" && article.Content != "" {
+			if err := content.PrepareArticle(&article); err != nil {
+				fail(c, err)
+				return
+			}
+		}
+		article.Content = article.ContentHTML
+		article.ContentFormat = content.FormatHTML
+	}
+
+	setETag(c, article.Version)
+	writeCacheable(c, article, article.UpdatedAt)
+}
+
+// GetArticleSection handles the GET request for a single section of an
+// article, identified by the anchor of the heading that starts it.
+func (h *Handler) GetArticleSection(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		fail(c, badRequest("invalid_id", "Invalid article ID"))
 		return
 	}
+	anchor := c.Param("anchor")
 
-	article, err := repository.GetArticleByID(id)
+	article, err := h.Articles.GetArticleByID(c.Request.Context(), id)
 	if err != nil {
-		// pgx.ErrNoRows is the error for no result found
-		if err.Error() == "no rows in result set" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+		fail(c, err)
+		return
+	}
+
+	if article.ContentHTML == "" && article.Content != "" {
+		if err := content.PrepareArticle(&article); err != nil {
+			fail(c, err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve article"})
+	}
+
+	section, found, err := content.Section(article.ContentHTML, anchor)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	if !found {
+		fail(c, &APIError{Status: http.StatusNotFound, Code: "section_not_found", Detail: "Section not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, article)
+	var heading models.TOCEntry
+	for _, entry := range article.TOC {
+		if entry.Anchor == anchor {
+			heading = entry
+			break
+		}
+	}
+
+	writeCacheable(c, gin.H{
+		"article_id": article.ID,
+		"anchor":     anchor,
+		"title":      heading.Text,
+		"level":      heading.Level,
+		"content":    section,
+	}, article.UpdatedAt)
 }
-// ... 其他 import 和函数 ...
+
 
 // SearchArticles handles the GET request for searching articles.
-func SearchArticles(c *gin.Context) {
+func (h *Handler) SearchArticles(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query cannot be empty"})
+		fail(c, badRequest("missing_query", "Search query cannot be empty"))
 		return
 	}
 
 	page, limit, offset := getPaginationParams(c)
-	articles, totalItems, err := repository.SearchArticles(query, limit, offset)
+	fields, err := getArticleFields(c)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	count, err := getCountMode(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to perform search"})
+		fail(c, err)
+		return
+	}
+
+	if cursor, ok := getCursorParam(c); ok {
+		articles, next, err := h.Articles.SearchArticlesAfter(c.Request.Context(), query, fields, cursor, limit)
+		if err != nil {
+			fail(c, err)
+			return
+		}
+		writeCacheable(c, models.CursorPaginatedResponse{
+			Data:       articles,
+			Pagination: models.CursorPagination{PageSize: limit, NextCursor: next, HasMore: next != ""},
+		}, time.Time{})
+		return
+	}
+
+	articles, info, err := h.Articles.SearchArticles(c.Request.Context(), query, fields, limit, offset, count)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
 	response := models.PaginatedResponse{
-		Data: articles,
-		Pagination: models.Pagination{
-			CurrentPage: page,
-			PageSize:    limit,
-			TotalItems:  totalItems,
-			TotalPages:  int(math.Ceil(float64(totalItems) / float64(limit))),
-		},
-	}
-	c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+		Data:       articles,
+		Pagination: newPagination(page, limit, count, info),
+	}
+	writeCacheable(c, response, time.Time{})
+}
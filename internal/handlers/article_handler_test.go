@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jalikey/zysj-backend/internal/models"
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+// listResponse is a page of an article list endpoint.
+type listResponse struct {
+	Data       []map[string]interface{} `json:"data"`
+	Pagination struct {
+		TotalItems *int64 `json:"totalItems"`
+		HasMore    bool   `json:"hasMore"`
+		NextCursor string `json:"nextCursor"`
+	} `json:"pagination"`
+}
+
+// createArticles adds articles from the given bodies through the admin API
+// and returns them.
+func createArticles(t *testing.T, router http.Handler, bodies ...string) []models.Article {
+	t.Helper()
+	articles := make([]models.Article, 0, len(bodies))
+	for _, body := range bodies {
+		w := serve(router, http.MethodPost, "/api/v1/admin/articles", body)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("creating %s: got status %d: %s", body, w.Code, w.Body)
+		}
+		var article models.Article
+		decode(t, w, &article)
+		articles = append(articles, article)
+	}
+	return articles
+}
+
+func TestGetArticleByID(t *testing.T) {
+	router, _ := newTestRouter()
+	article := createArticles(t, router, `{"title":"One","content":"**bold**","content_format":"markdown"}`)[0]
+	target := fmt.Sprintf("/api/v1/articles/%d", article.ID)
+
+	tests := []struct {
+		name        string
+		target      string
+		wantStatus  int
+		wantCode    string
+		wantContent string
+	}{
+		{"raw", target, http.StatusOK, "", "**bold**"},
+		{"html", target + "?format=html", http.StatusOK, "", "<p><strong>bold</strong></p>\n"},
+		{"bad format", target + "?format=pdf", http.StatusBadRequest, "invalid_format", ""},
+		{"invalid id", "/api/v1/articles/x", http.StatusBadRequest, "invalid_id", ""},
+		{"unknown id", "/api/v1/articles/999", http.StatusNotFound, "article_not_found", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, tt.target, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantCode != "" {
+				if code := problemCode(t, w); code != tt.wantCode {
+					t.Errorf("got code %q, want %q", code, tt.wantCode)
+				}
+				return
+			}
+			var got models.Article
+			decode(t, w, &got)
+			if got.Content != tt.wantContent {
+				t.Errorf("got content %q, want %q", got.Content, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestGetArticleByIDNotModified(t *testing.T) {
+	router, _ := newTestRouter()
+	article := createArticles(t, router, `{"title":"One","content":"text"}`)[0]
+	target := fmt.Sprintf("/api/v1/articles/%d", article.ID)
+
+	w := serve(router, http.MethodGet, target, "")
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	if w := serve(router, http.MethodGet, target, "", "If-None-Match", etag); w.Code != http.StatusNotModified {
+		t.Errorf("got status %d, want 304", w.Code)
+	}
+}
+
+func TestGetArticles(t *testing.T) {
+	router, _ := newTestRouter()
+	createArticles(t, router,
+		`{"title":"One","content":"text"}`,
+		`{"title":"Two","content":"text"}`,
+		`{"title":"Three","content":"text"}`,
+	)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantCode   string
+		wantLen    int
+		wantKeys   int
+		wantTotal  bool
+		wantMore   bool
+	}{
+		{"first page", "?limit=2", http.StatusOK, "", 2, 0, true, true},
+		{"last page", "?limit=2&page=2", http.StatusOK, "", 1, 0, true, false},
+		{"projection", "?fields=title,title", http.StatusOK, "", 3, 2, true, false},
+		{"no count", "?count=none&limit=1", http.StatusOK, "", 1, 0, false, true},
+		{"estimated count", "?count=estimate&limit=1", http.StatusOK, "", 1, 0, true, true},
+		{"unknown field", "?fields=password", http.StatusBadRequest, "invalid_fields", 0, 0, false, false},
+		{"bad count", "?count=all", http.StatusBadRequest, "invalid_count", 0, 0, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, "/api/v1/articles"+tt.query, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantCode != "" {
+				if code := problemCode(t, w); code != tt.wantCode {
+					t.Errorf("got code %q, want %q", code, tt.wantCode)
+				}
+				return
+			}
+			var page listResponse
+			decode(t, w, &page)
+			if len(page.Data) != tt.wantLen {
+				t.Errorf("got %d articles, want %d", len(page.Data), tt.wantLen)
+			}
+			if tt.wantKeys > 0 && len(page.Data[0]) != tt.wantKeys {
+				t.Errorf("got fields %v, want %d of them", page.Data[0], tt.wantKeys)
+			}
+			if (page.Pagination.TotalItems != nil) != tt.wantTotal {
+				t.Errorf("got total %v, want one: %v", page.Pagination.TotalItems, tt.wantTotal)
+			}
+			if page.Pagination.HasMore != tt.wantMore {
+				t.Errorf("got hasMore %v, want %v", page.Pagination.HasMore, tt.wantMore)
+			}
+		})
+	}
+}
+
+func TestNewPagination(t *testing.T) {
+	tests := []struct {
+		name          string
+		count         repository.CountMode
+		info          repository.PageInfo
+		wantTotal     int64
+		wantPages     int
+		wantEstimated bool
+	}{
+		{"exact", repository.CountExact, repository.PageInfo{Total: 21, HasMore: true}, 21, 3, false},
+		{"exact, no rows", repository.CountExact, repository.PageInfo{}, 0, 0, false},
+		{"estimate", repository.CountEstimate, repository.PageInfo{Total: 1000, Estimated: true, HasMore: true}, 1000, 100, true},
+		{"none", repository.CountNone, repository.PageInfo{HasMore: true}, -1, -1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPagination(2, 10, tt.count, tt.info)
+			if p.CurrentPage != 2 || p.PageSize != 10 || p.HasMore != tt.info.HasMore {
+				t.Errorf("got page %d, size %d, hasMore %v", p.CurrentPage, p.PageSize, p.HasMore)
+			}
+			if p.TotalsEstimated != tt.wantEstimated {
+				t.Errorf("got totalsEstimated %v, want %v", p.TotalsEstimated, tt.wantEstimated)
+			}
+			if tt.wantTotal < 0 {
+				if p.TotalItems != nil || p.TotalPages != nil {
+					t.Errorf("got totals %v and %v, want none", p.TotalItems, p.TotalPages)
+				}
+				return
+			}
+			if p.TotalItems == nil || *p.TotalItems != tt.wantTotal || p.TotalPages == nil || *p.TotalPages != tt.wantPages {
+				t.Errorf("got totals %v and %v, want %d and %d", p.TotalItems, p.TotalPages, tt.wantTotal, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestGetArticlesCursor(t *testing.T) {
+	router, _ := newTestRouter()
+	createArticles(t, router,
+		`{"title":"One","content":"text"}`,
+		`{"title":"Two","content":"text"}`,
+		`{"title":"Three","content":"text"}`,
+	)
+
+	seen := map[float64]bool{}
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination doesn't end")
+		}
+		w := serve(router, http.MethodGet, "/api/v1/articles?limit=2&cursor="+cursor, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d: %s", w.Code, w.Body)
+		}
+		var page listResponse
+		decode(t, w, &page)
+		for _, a := range page.Data {
+			id := a["id"].(float64)
+			if seen[id] {
+				t.Errorf("article %v listed twice", id)
+			}
+			seen[id] = true
+		}
+		if !page.Pagination.HasMore {
+			break
+		}
+		cursor = page.Pagination.NextCursor
+	}
+	if len(seen) != 3 {
+		t.Errorf("got %d articles across pages, want 3", len(seen))
+	}
+
+	// Cursors are bound to the listing that issued them
+	category := createCategory(t, router, `{"name":"Herbs"}`)
+	other := createCategory(t, router, `{"name":"Needles"}`)
+	createArticles(t, router,
+		fmt.Sprintf(`{"title":"Ginseng","content":"root text","category_id":%d}`, category.ID),
+		fmt.Sprintf(`{"title":"Licorice","content":"root text","category_id":%d}`, category.ID),
+	)
+	var categoryPage struct {
+		Articles listResponse `json:"articles"`
+	}
+	decode(t, serve(router, http.MethodGet, "/api/v1/categories/"+category.Slug+"?limit=1&cursor=", ""), &categoryPage)
+	categoryCursor := categoryPage.Articles.Pagination.NextCursor
+	var searchPage listResponse
+	decode(t, serve(router, http.MethodGet, "/api/v1/search?q=root&limit=1&cursor=", ""), &searchPage)
+	searchCursor := searchPage.Pagination.NextCursor
+	if categoryCursor == "" || searchCursor == "" {
+		t.Fatalf("got category cursor %q and search cursor %q", categoryCursor, searchCursor)
+	}
+	if w := serve(router, http.MethodGet, "/api/v1/categories/"+category.Slug+"?cursor="+categoryCursor, ""); w.Code != http.StatusOK {
+		t.Errorf("category cursor on its own listing: got status %d: %s", w.Code, w.Body)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{"malformed", "/api/v1/articles?cursor=%21%21"},
+		{"articles on search", "/api/v1/search?q=text&cursor=" + cursor},
+		{"articles on category", "/api/v1/categories/" + category.Slug + "?cursor=" + cursor},
+		{"category on articles", "/api/v1/articles?cursor=" + categoryCursor},
+		{"category on another category", "/api/v1/categories/" + other.Slug + "?cursor=" + categoryCursor},
+		{"search on another query", "/api/v1/search?q=text&cursor=" + searchCursor},
+		{"search on articles", "/api/v1/articles?cursor=" + searchCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, tt.target, "")
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("got status %d, want 400: %s", w.Code, w.Body)
+			}
+			if code := problemCode(t, w); code != "invalid_cursor" {
+				t.Errorf("got code %q, want invalid_cursor", code)
+			}
+		})
+	}
+}
+
+func TestGetArticleBySlug(t *testing.T) {
+	router, _ := newTestRouter()
+	article := createArticles(t, router, `{"title":"Old Name","content":"text"}`)[0]
+	w := serve(router, http.MethodPatch, fmt.Sprintf("/api/v1/admin/articles/%d", article.ID), `{"slug":"new-name"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("renaming: got status %d: %s", w.Code, w.Body)
+	}
+
+	tests := []struct {
+		name         string
+		target       string
+		wantStatus   int
+		wantLocation string
+	}{
+		{"current slug", "/api/v1/articles/by-slug/new-name", http.StatusOK, ""},
+		{"old slug", "/api/v1/articles/by-slug/old-name?format=html", http.StatusMovedPermanently, "/api/v1/articles/by-slug/new-name?format=html"},
+		{"unknown slug", "/api/v1/articles/by-slug/nothing", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, tt.target, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if loc := w.Header().Get("Location"); loc != tt.wantLocation {
+				t.Errorf("got Location %q, want %q", loc, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestGetArticleSection(t *testing.T) {
+	router, _ := newTestRouter()
+	article := createArticles(t, router, `{"title":"Doc","content_format":"markdown","content":"# Intro\n\nhello\n\n# Usage\n\nworld"}`)[0]
+	if len(article.TOC) != 2 {
+		t.Fatalf("got TOC %v, want two headings", article.TOC)
+	}
+
+	tests := []struct {
+		name       string
+		anchor     string
+		wantStatus int
+		wantTitle  string
+	}{
+		{"first", article.TOC[0].Anchor, http.StatusOK, "Intro"},
+		{"second", article.TOC[1].Anchor, http.StatusOK, "Usage"},
+		{"unknown", "nowhere", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, fmt.Sprintf("/api/v1/articles/%d/sections/%s", article.ID, tt.anchor), "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if code := problemCode(t, w); code != "section_not_found" {
+					t.Errorf("got code %q, want section_not_found", code)
+				}
+				return
+			}
+			var section struct {
+				Title string `json:"title"`
+			}
+			decode(t, w, &section)
+			if section.Title != tt.wantTitle {
+				t.Errorf("got title %q, want %q", section.Title, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestSearchArticles(t *testing.T) {
+	router, _ := newTestRouter()
+	createArticles(t, router,
+		`{"title":"Ginseng","content":"root"}`,
+		`{"title":"Tea","content":"leaf"}`,
+	)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantLen    int
+	}{
+		{"match", "?q=ginseng", http.StatusOK, 1},
+		{"no match", "?q=coffee", http.StatusOK, 0},
+		{"missing query", "", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, "/api/v1/search"+tt.query, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var page listResponse
+			decode(t, w, &page)
+			if len(page.Data) != tt.wantLen {
+				t.Errorf("got %d results, want %d", len(page.Data), tt.wantLen)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jalikey/zysj-backend/internal/config"
+)
+
+// Public reads are sent with validators so that clients and CDNs can
+// revalidate instead of downloading the body again: single articles carry
+// their version as the ETag and updated_at as Last-Modified, other
+// responses a hash of the body as the ETag. Lists have no Last-Modified, as
+// a deletion would not move it forward.
+
+// PublicCache sets the Cache-Control header configured for public content.
+// Error responses replace it with no-store.
+func PublicCache(cfg config.CacheConfig) gin.HandlerFunc {
+	value := cacheControl(cfg)
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", value)
+		c.Next()
+	}
+}
+
+// cacheControl builds the Cache-Control value for cfg.
+func cacheControl(cfg config.CacheConfig) string {
+	if cfg.MaxAge == 0 && cfg.SharedMaxAge == 0 {
+		return "public, no-cache"
+	}
+	seconds := func(d config.Duration) string {
+		return strconv.FormatInt(int64(time.Duration(d)/time.Second), 10)
+	}
+	parts := []string{"public", "max-age=" + seconds(cfg.MaxAge)}
+	if cfg.SharedMaxAge > 0 {
+		parts = append(parts, "s-maxage="+seconds(cfg.SharedMaxAge))
+	}
+	if cfg.StaleWhileRevalidate > 0 {
+		parts = append(parts, "stale-while-revalidate="+seconds(cfg.StaleWhileRevalidate))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeCacheable sends obj as a 200 JSON response, or a bodyless 304 when
+// the request's conditional headers show the client already has it. The
+// ETag is the one set by the handler, if any, or a hash of the body;
+// lastModified is sent unless it is zero.
+func writeCacheable(c *gin.Context, obj interface{}, lastModified time.Time) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	etag := c.Writer.Header().Get("ETag")
+	if etag == "" {
+		sum := sha256.Sum256(body)
+		etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+		c.Header("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// notModified evaluates If-None-Match and, only in its absence,
+// If-Modified-Since, as RFC 9110 prescribes for GET requests.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for _, tag := range strings.Split(header, ",") {
+			tag = strings.TrimSpace(tag)
+			// If-None-Match uses weak comparison
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
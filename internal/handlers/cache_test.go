@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jalikey/zysj-backend/internal/config"
+)
+
+func TestCacheControl(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.CacheConfig
+		want string
+	}{
+		{"revalidate", config.CacheConfig{}, "public, no-cache"},
+		{"browsers only", config.CacheConfig{MaxAge: config.Duration(time.Minute)}, "public, max-age=60"},
+		{"shared only", config.CacheConfig{SharedMaxAge: config.Duration(5 * time.Minute)}, "public, max-age=0, s-maxage=300"},
+		{
+			"everything",
+			config.CacheConfig{
+				MaxAge:               config.Duration(time.Minute),
+				SharedMaxAge:         config.Duration(5 * time.Minute),
+				StaleWhileRevalidate: config.Duration(30 * time.Second),
+			},
+			"public, max-age=60, s-maxage=300, stale-while-revalidate=30",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cacheControl(tt.cfg); got != tt.want {
+				t.Errorf("cacheControl() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	const etag = `"abc"`
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+
+	tests := []struct {
+		name         string
+		headers      []string
+		lastModified time.Time
+		want         bool
+	}{
+		{"no validators", nil, modified, false},
+		{"matching tag", []string{"If-None-Match", etag}, modified, true},
+		{"weak match", []string{"If-None-Match", `W/"abc"`}, modified, true},
+		{"one of several", []string{"If-None-Match", `"x", "abc"`}, modified, true},
+		{"any", []string{"If-None-Match", "*"}, modified, true},
+		{"other tag", []string{"If-None-Match", `"x"`}, modified, false},
+		{"tag wins over date", []string{"If-None-Match", `"x"`, "If-Modified-Since", modified.Format(http.TimeFormat)}, modified, false},
+		{"same second", []string{"If-Modified-Since", modified.Format(http.TimeFormat)}, modified, true},
+		{"later", []string{"If-Modified-Since", modified.Add(time.Hour).Format(http.TimeFormat)}, modified, true},
+		{"earlier", []string{"If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat)}, modified, false},
+		{"bad date", []string{"If-Modified-Since", "yesterday"}, modified, false},
+		{"no last modified", []string{"If-Modified-Since", modified.Format(http.TimeFormat)}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for i := 0; i+1 < len(tt.headers); i += 2 {
+				req.Header.Set(tt.headers[i], tt.headers[i+1])
+			}
+			if got := notModified(req, etag, tt.lastModified); got != tt.want {
+				t.Errorf("notModified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetArticlesNotModified(t *testing.T) {
+	router, _ := newTestRouter()
+	createArticles(t, router, `{"title":"One","content":"text"}`)
+
+	w := serve(router, http.MethodGet, "/api/v1/articles", "")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("got status %d and ETag %q", w.Code, etag)
+	}
+	if lm := w.Header().Get("Last-Modified"); lm != "" {
+		t.Errorf("list sent Last-Modified %q", lm)
+	}
+
+	w = serve(router, http.MethodGet, "/api/v1/articles", "", "If-None-Match", etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("got status %d with %d bytes, want an empty 304", w.Code, w.Body.Len())
+	}
+
+	// A new article changes the list, and so its ETag
+	createArticles(t, router, `{"title":"Two","content":"text"}`)
+	w = serve(router, http.MethodGet, "/api/v1/articles", "", "If-None-Match", etag)
+	if w.Code != http.StatusOK {
+		t.Errorf("after a change: got status %d, want 200", w.Code)
+	}
+}
@@ -1,27 +1,17 @@
 package handlers
 
 import (
-	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jalikey/zysj-backend/internal/repository" // !! 修改为你的模块路径
 	"github.com/jalikey/zysj-backend/internal/models"
 )
-// Helper function to parse pagination (can be moved to a shared package)
-func getPaginationParams(c *gin.Context) (page, limit, offset int) {
-	page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ = strconv.Atoi(c.DefaultQuery("limit", "10"))
-	if page < 1 { page = 1 }
-	if limit < 1 { limit = 10 }
-	if limit > 100 { limit = 100 }
-	offset = (page - 1) * limit
-	return
-}
+
 // GetCategories handles the GET request for retrieving all categories.
-func GetCategories(c *gin.Context) {
-	categories, err := repository.GetAllCategories()
+func (h *Handler) GetCategories(c *gin.Context) {
+	categories, err := h.Categories.GetAllCategories(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories"})
+		fail(c, err)
 		return
 	}
 
@@ -30,45 +20,66 @@ func GetCategories(c *gin.Context) {
 		categories = []models.Category{}
 	}
 
-	c.JSON(http.StatusOK, categories)
+	writeCacheable(c, categories, time.Time{})
 }
 // ... GetCategories() 函数保持不变 ...
 
 // GetArticlesByCategory handles getting all articles for a specific category.
-func GetArticlesByCategory(c *gin.Context) {
+// Slugs the category used before being renamed redirect permanently to its
+// current one, keeping the query string.
+func (h *Handler) GetArticlesByCategory(c *gin.Context) {
 	slug := c.Param("slug")
 
-	category, err := repository.GetCategoryBySlug(slug)
+	category, err := h.Categories.GetCategoryBySlug(c.Request.Context(), slug)
 	if err != nil {
-		// ... error handling remains the same ...
-		if err.Error() == "no rows in result set" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
-			return
+		if err := slugRedirect(c, slug, err, h.Categories.CategorySlugRedirect); err != nil {
+			fail(c, err)
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find category"})
 		return
 	}
 
 	page, limit, offset := getPaginationParams(c)
-	articles, totalItems, err := repository.GetArticlesByCategoryID(category.ID, limit, offset)
+	fields, err := getArticleFields(c)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	count, err := getCountMode(c)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	if cursor, ok := getCursorParam(c); ok {
+		articles, next, err := h.Articles.GetArticlesByCategoryIDAfter(c.Request.Context(), category.ID, fields, cursor, limit)
+		if err != nil {
+			fail(c, err)
+			return
+		}
+		writeCacheable(c, gin.H{
+			"category": category,
+			"articles": models.CursorPaginatedResponse{
+				Data:       articles,
+				Pagination: models.CursorPagination{PageSize: limit, NextCursor: next, HasMore: next != ""},
+			},
+		}, time.Time{})
+		return
+	}
+
+	articles, info, err := h.Articles.GetArticlesByCategoryID(c.Request.Context(), category.ID, fields, limit, offset, count)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve articles for this category"})
+		fail(c, err)
 		return
 	}
 	
 	// We wrap the original response in a new structure
 	paginatedArticles := models.PaginatedResponse{
-		Data: articles,
-		Pagination: models.Pagination{
-			CurrentPage: page,
-			PageSize:    limit,
-			TotalItems:  totalItems,
-			TotalPages:  int(math.Ceil(float64(totalItems) / float64(limit))),
-		},
+		Data:       articles,
+		Pagination: newPagination(page, limit, count, info),
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	writeCacheable(c, gin.H{
 		"category": category,
 		"articles": paginatedArticles,
-	})
+	}, time.Time{})
 }
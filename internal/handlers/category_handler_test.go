@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jalikey/zysj-backend/internal/models"
+)
+
+// createCategory adds a category from body through the admin API and
+// returns it.
+func createCategory(t *testing.T, router http.Handler, body string) models.Category {
+	t.Helper()
+	w := serve(router, http.MethodPost, "/api/v1/admin/categories", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("creating %s: got status %d: %s", body, w.Code, w.Body)
+	}
+	var categories []models.Category
+	decode(t, serve(router, http.MethodGet, "/api/v1/categories", ""), &categories)
+	latest := categories[0]
+	for _, c := range categories {
+		if c.ID > latest.ID {
+			latest = c
+		}
+	}
+	return latest
+}
+
+func TestCreateCategory(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantSlug   string
+		wantField  string
+	}{
+		{"generated slug", `{"name":"Herbal Medicine"}`, http.StatusCreated, "herbal-medicine", ""},
+		{"given slug", `{"name":"Herbs","slug":"herbs"}`, http.StatusCreated, "herbs", ""},
+		{"missing name", `{"description":"x"}`, http.StatusUnprocessableEntity, "", "name"},
+		{"unknown parent", `{"name":"Herbs","parent_id":42}`, http.StatusUnprocessableEntity, "", "parent_id"},
+		{"negative parent", `{"name":"Herbs","parent_id":-1}`, http.StatusUnprocessableEntity, "", "parent_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := newTestRouter()
+			w := serve(router, http.MethodPost, "/api/v1/admin/categories", tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantField != "" {
+				var p Problem
+				decode(t, w, &p)
+				if len(p.Errors) != 1 || p.Errors[0].Field != tt.wantField {
+					t.Errorf("got field errors %+v, want one for %s", p.Errors, tt.wantField)
+				}
+				return
+			}
+			var categories []models.Category
+			decode(t, serve(router, http.MethodGet, "/api/v1/categories", ""), &categories)
+			if len(categories) != 1 || categories[0].Slug != tt.wantSlug {
+				t.Errorf("got categories %+v, want one with slug %q", categories, tt.wantSlug)
+			}
+		})
+	}
+}
+
+func TestPatchCategoryParent(t *testing.T) {
+	router, _ := newTestRouter()
+	root := createCategory(t, router, `{"name":"Root"}`)
+	child := createCategory(t, router, fmt.Sprintf(`{"name":"Child","parent_id":%d}`, root.ID))
+	grandchild := createCategory(t, router, fmt.Sprintf(`{"name":"Grandchild","parent_id":%d}`, child.ID))
+
+	tests := []struct {
+		name       string
+		id         int64
+		parent     int64
+		wantStatus int
+	}{
+		{"itself", root.ID, root.ID, http.StatusUnprocessableEntity},
+		{"own child", root.ID, child.ID, http.StatusUnprocessableEntity},
+		{"own grandchild", root.ID, grandchild.ID, http.StatusUnprocessableEntity},
+		{"move up", grandchild.ID, root.ID, http.StatusOK},
+		{"top level", child.ID, 0, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPatch, fmt.Sprintf("/api/v1/admin/categories/%d", tt.id), fmt.Sprintf(`{"parent_id":%d}`, tt.parent))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+}
+
+func TestGetArticlesByCategory(t *testing.T) {
+	router, _ := newTestRouter()
+	herbs := createCategory(t, router, `{"name":"Herbs"}`)
+	teas := createCategory(t, router, `{"name":"Teas"}`)
+	createArticles(t, router,
+		fmt.Sprintf(`{"title":"Ginseng","content":"text","category_id":%d}`, herbs.ID),
+		fmt.Sprintf(`{"title":"Angelica","content":"text","category_id":%d}`, herbs.ID),
+		fmt.Sprintf(`{"title":"Oolong","content":"text","category_id":%d}`, teas.ID),
+	)
+
+	var first struct {
+		Articles listResponse `json:"articles"`
+	}
+	decode(t, serve(router, http.MethodGet, "/api/v1/categories/herbs?limit=1&cursor=", ""), &first)
+	if len(first.Articles.Data) != 1 || first.Articles.Pagination.NextCursor == "" {
+		t.Fatalf("got first page %+v", first.Articles)
+	}
+	herbsCursor := first.Articles.Pagination.NextCursor
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantLen    int
+	}{
+		{"offset", "/api/v1/categories/herbs", http.StatusOK, 2},
+		{"other category", "/api/v1/categories/teas", http.StatusOK, 1},
+		{"next page", "/api/v1/categories/herbs?limit=1&cursor=" + herbsCursor, http.StatusOK, 1},
+		{"cursor of another category", "/api/v1/categories/teas?cursor=" + herbsCursor, http.StatusBadRequest, 0},
+		{"cursor of all articles", "/api/v1/articles?cursor=" + herbsCursor, http.StatusBadRequest, 0},
+		{"unknown category", "/api/v1/categories/coffee", http.StatusNotFound, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, tt.target, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got struct {
+				Articles listResponse `json:"articles"`
+			}
+			decode(t, w, &got)
+			if len(got.Articles.Data) != tt.wantLen {
+				t.Errorf("got %d articles, want %d", len(got.Articles.Data), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestDeleteCategory(t *testing.T) {
+	router, _ := newTestRouter()
+	category := createCategory(t, router, `{"name":"Gone"}`)
+	target := fmt.Sprintf("/api/v1/admin/categories/%d", category.ID)
+
+	if w := serve(router, http.MethodDelete, target, ""); w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body)
+	}
+	w := serve(router, http.MethodGet, target, "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("got status %d after deletion, want 404", w.Code)
+	}
+	if code := problemCode(t, w); code != "category_not_found" {
+		t.Errorf("got code %q, want category_not_found", code)
+	}
+}
+
+func TestUpdateCategory(t *testing.T) {
+	router, _ := newTestRouter()
+	category := createCategory(t, router, `{"name":"Herbs"}`)
+	target := fmt.Sprintf("/api/v1/admin/categories/%d", category.ID)
+
+	tests := []struct {
+		name       string
+		ifMatch    string
+		wantStatus int
+		wantCode   string
+	}{
+		{"missing If-Match", "", http.StatusPreconditionRequired, "precondition_required"},
+		{"stale version", `"7"`, http.StatusPreconditionFailed, "precondition_failed"},
+		{"current version", versionETag(category.Version), http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPut, target, `{"name":"Roots"}`, "If-Match", tt.ifMatch)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantCode != "" {
+				if code := problemCode(t, w); code != tt.wantCode {
+					t.Errorf("got code %q, want %q", code, tt.wantCode)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/jalikey/zysj-backend/internal/logging"
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+// Errors are reported as RFC 7807 problem details. Handlers don't write
+// error bodies themselves: they call fail, and ErrorHandler turns the error
+// into a Problem once the handler chain returns.
+
+// ProblemContentType is the media type of error responses.
+const ProblemContentType = "application/problem+json"
+
+// StatusClientClosedRequest is the non-standard status (popularised by
+// nginx) recorded when the client went away before the response was ready.
+const StatusClientClosedRequest = 499
+
+// queryCanceled is the SQLSTATE Postgres reports for a cancelled statement.
+const queryCanceled = "57014"
+
+// Problem is an RFC 7807 problem details object. Code is a stable,
+// machine-readable identifier that clients can rely on; Title and Detail
+// are meant for humans and may change.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError points at a single invalid field of the request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// APIError is an error raised by a handler that already knows its HTTP
+// status and problem code.
+type APIError struct {
+	Status int
+	Code   string
+	Detail string
+	Fields []FieldError
+	Err    error
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Detail, e.Err)
+	}
+	return e.Detail
+}
+
+func (e *APIError) Unwrap() error { return e.Err }
+
+// badRequest returns a 400 error with the given problem code.
+func badRequest(code, detail string) *APIError {
+	return &APIError{Status: http.StatusBadRequest, Code: code, Detail: detail}
+}
+
+// unauthorized returns a 401 error for a missing or invalid credential.
+func unauthorized(detail string) *APIError {
+	return &APIError{Status: http.StatusUnauthorized, Code: "unauthorized", Detail: detail}
+}
+
+// fail records err as the outcome of the request and stops the handler
+// chain; ErrorHandler writes the response.
+func fail(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// ErrorHandler renders the last error recorded with fail as a problem
+// response, unless a response has already been written.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		writeProblem(c, problemFor(c, c.Errors.Last().Err))
+	}
+}
+
+// NotFound answers requests for routes that don't exist.
+func NotFound(c *gin.Context) {
+	fail(c, &APIError{Status: http.StatusNotFound, Code: "route_not_found", Detail: "No route matches " + c.Request.URL.Path})
+}
+
+// problemFor maps an error to its problem details.
+func problemFor(c *gin.Context, err error) Problem {
+	ctx := c.Request.Context()
+
+	var apiErr *APIError
+	var repoErr *repository.Error
+	switch {
+	case errors.As(err, &apiErr):
+		if apiErr.Status >= http.StatusInternalServerError {
+			slog.ErrorContext(ctx, "Request failed", "error", err)
+		}
+		return newProblem(apiErr.Status, apiErr.Code, apiErr.Detail, apiErr.Fields)
+
+	case errors.Is(err, repository.ErrInvalidCursor):
+		return newProblem(http.StatusBadRequest, "invalid_cursor", "The cursor is malformed or belongs to another listing", nil)
+
+	case errors.As(err, &repoErr):
+		return repositoryProblem(repoErr)
+
+	case isCanceled(ctx, err, context.DeadlineExceeded):
+		slog.WarnContext(ctx, "Request timed out", "error", err)
+		return newProblem(http.StatusGatewayTimeout, "timeout", "The request took too long to complete", nil)
+
+	case isCanceled(ctx, err, context.Canceled):
+		return newProblem(StatusClientClosedRequest, "client_closed_request", "The client closed the request", nil)
+	}
+
+	slog.ErrorContext(ctx, "Request failed", "error", err)
+	return newProblem(http.StatusInternalServerError, "internal_error", "An unexpected error occurred", nil)
+}
+
+// repositoryProblem maps a classified store error to its problem details.
+func repositoryProblem(err *repository.Error) Problem {
+	var fields []FieldError
+	field := func(code string) {
+		if err.Field != "" {
+			fields = []FieldError{{Field: err.Field, Code: code, Message: err.Error()}}
+		}
+	}
+
+	switch err.Kind {
+	case repository.ErrNotFound:
+		code := strings.ReplaceAll(strings.ToLower(err.Entity), " ", "_") + "_not_found"
+		return newProblem(http.StatusNotFound, code, capitalize(err.Error()), nil)
+	case repository.ErrConflict:
+		field("duplicate")
+		return newProblem(http.StatusConflict, "conflict", capitalize(err.Error()), fields)
+	case repository.ErrForeignKey:
+		field("invalid_reference")
+		return newProblem(http.StatusUnprocessableEntity, "invalid_reference", capitalize(err.Error()), fields)
+	case repository.ErrVersionMismatch:
+		return newProblem(http.StatusPreconditionFailed, "precondition_failed", capitalize(err.Error()), nil)
+	default:
+		field("invalid")
+		return newProblem(http.StatusUnprocessableEntity, "validation_failed", capitalize(err.Error()), fields)
+	}
+}
+
+func newProblem(status int, code, detail string, fields []FieldError) Problem {
+	title := http.StatusText(status)
+	if status == StatusClientClosedRequest {
+		title = "Client Closed Request"
+	}
+	return Problem{
+		Type:   "/problems/" + strings.ReplaceAll(code, "_", "-"),
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Code:   code,
+		Errors: fields,
+	}
+}
+
+// writeProblem sends p as the response, tagged with the request path and ID.
+// Errors are never cached, whatever the route's caching policy.
+func writeProblem(c *gin.Context, p Problem) {
+	p.Instance = c.Request.URL.Path
+	p.RequestID = logging.RequestID(c.Request.Context())
+	c.Header("Content-Type", ProblemContentType)
+	c.Header("Cache-Control", "no-store")
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// invalidPayload turns a request binding error into a 400 or 422 error,
+// listing the failed validation rules per field.
+func invalidPayload(err error) *APIError {
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		fields := make([]FieldError, 0, len(verrs))
+		for _, fe := range verrs {
+			fields = append(fields, FieldError{
+				Field:   fe.Field(),
+				Code:    fe.Tag(),
+				Message: validationMessage(fe),
+			})
+		}
+		return &APIError{
+			Status: http.StatusUnprocessableEntity,
+			Code:   "validation_failed",
+			Detail: "The request body failed validation",
+			Fields: fields,
+			Err:    err,
+		}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &APIError{
+			Status: http.StatusBadRequest,
+			Code:   "invalid_type",
+			Detail: "The request body has a field of the wrong type",
+			Fields: []FieldError{{Field: typeErr.Field, Code: "type", Message: "must be a " + typeErr.Type.String()}},
+			Err:    err,
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		return &APIError{Status: http.StatusBadRequest, Code: "empty_body", Detail: "The request body is empty", Err: err}
+	}
+	return &APIError{Status: http.StatusBadRequest, Code: "malformed_body", Detail: "The request body is not valid JSON", Err: err}
+}
+
+// isCanceled reports whether err was caused by the context ending with
+// cause. Postgres may report the cancellation as a query_canceled error
+// rather than the context error itself, so the context is checked too.
+func isCanceled(ctx context.Context, err error, cause error) bool {
+	if errors.Is(err, cause) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == queryCanceled || pgconn.Timeout(err) {
+		return errors.Is(ctx.Err(), cause)
+	}
+	return false
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+func TestErrorHandler(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	pgCanceled := &pgconn.PgError{Code: queryCanceled}
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		err        error
+		wantStatus int
+		wantCode   string
+		wantField  string
+	}{
+		{"api error", nil, badRequest("invalid_id", "Invalid article ID"), http.StatusBadRequest, "invalid_id", ""},
+		{"wrapped api error", nil, fmt.Errorf("saving: %w", unauthorized("no token")), http.StatusUnauthorized, "unauthorized", ""},
+		{"invalid cursor", nil, fmt.Errorf("decoding: %w", repository.ErrInvalidCursor), http.StatusBadRequest, "invalid_cursor", ""},
+		{"not found", nil, &repository.Error{Kind: repository.ErrNotFound, Entity: "article"}, http.StatusNotFound, "article_not_found", ""},
+		{"API key not found", nil, &repository.Error{Kind: repository.ErrNotFound, Entity: "API key"}, http.StatusNotFound, "api_key_not_found", ""},
+		{"conflict", nil, &repository.Error{Kind: repository.ErrConflict, Entity: "category", Field: "slug"}, http.StatusConflict, "conflict", "slug"},
+		{"foreign key", nil, &repository.Error{Kind: repository.ErrForeignKey, Entity: "article", Field: "category_id"}, http.StatusUnprocessableEntity, "invalid_reference", "category_id"},
+		{"version mismatch", nil, &repository.Error{Kind: repository.ErrVersionMismatch, Entity: "article"}, http.StatusPreconditionFailed, "precondition_failed", ""},
+		{"validation", nil, &repository.Error{Kind: repository.ErrValidation, Entity: "article", Field: "title"}, http.StatusUnprocessableEntity, "validation_failed", "title"},
+		{"deadline", expired, context.DeadlineExceeded, http.StatusGatewayTimeout, "timeout", ""},
+		{"query canceled by deadline", expired, pgCanceled, http.StatusGatewayTimeout, "timeout", ""},
+		{"client gone", canceled, context.Canceled, StatusClientClosedRequest, "client_closed_request", ""},
+		{"query canceled by client", canceled, pgCanceled, StatusClientClosedRequest, "client_closed_request", ""},
+		{"query canceled otherwise", nil, pgCanceled, http.StatusInternalServerError, "internal_error", ""},
+		{"unknown", nil, errors.New("boom"), http.StatusInternalServerError, "internal_error", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.GET("/fail", func(c *gin.Context) { fail(c, tt.err) })
+
+			req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+			if tt.ctx != nil {
+				req = req.WithContext(tt.ctx)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if cc := w.Header().Get("Cache-Control"); cc != "no-store" {
+				t.Errorf("got Cache-Control %q, want no-store", cc)
+			}
+			var p Problem
+			decode(t, w, &p)
+			if p.Code != tt.wantCode || p.Status != tt.wantStatus || p.Instance != "/fail" {
+				t.Errorf("got code %q, status %d, instance %q", p.Code, p.Status, p.Instance)
+			}
+			if p.Type != "/problems/"+strings.ReplaceAll(tt.wantCode, "_", "-") {
+				t.Errorf("got type %q for code %q", p.Type, tt.wantCode)
+			}
+			var field string
+			if len(p.Errors) > 0 {
+				field = p.Errors[0].Field
+			}
+			if field != tt.wantField {
+				t.Errorf("got field %q, want %q", field, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestErrorHandlerKeepsWrittenResponse(t *testing.T) {
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+		c.Writer.WriteHeaderNow()
+		_ = c.Error(errors.New("after the response"))
+	})
+	w := serve(router, http.MethodGet, "/", "")
+	if w.Code != http.StatusAccepted || w.Body.Len() != 0 {
+		t.Errorf("got status %d and body %q, want the handler's response", w.Code, w.Body)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	router, _ := newTestRouter()
+	w := serve(router, http.MethodGet, "/api/v1/nowhere", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("got status %d, want 404", w.Code)
+	}
+	if code := problemCode(t, w); code != "route_not_found" {
+		t.Errorf("got code %q, want route_not_found", code)
+	}
+}
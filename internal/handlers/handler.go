@@ -0,0 +1,17 @@
+package handlers
+
+import "github.com/jalikey/zysj-backend/internal/repository"
+
+// Handler holds the dependencies shared by the HTTP handlers, which are
+// registered on the router as its methods.
+type Handler struct {
+	Articles   repository.ArticleStore
+	Categories repository.CategoryStore
+	Users      repository.UserStore
+	APIKeys    repository.APIKeyStore
+}
+
+// New returns a Handler backed by the given stores.
+func New(articles repository.ArticleStore, categories repository.CategoryStore, users repository.UserStore, apiKeys repository.APIKeyStore) *Handler {
+	return &Handler{Articles: articles, Categories: categories, Users: users, APIKeys: apiKeys}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestRouter serves the public and admin routes from a fresh
+// MemoryStore, without authentication or rate limits.
+func newTestRouter() (*gin.Engine, *repository.MemoryStore) {
+	store := repository.NewMemoryStore()
+	return testRoutes(New(store, store, store, store)), store
+}
+
+// testRoutes registers the routes of newTestRouter on h.
+func testRoutes(h *Handler) *gin.Engine {
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.NoRoute(NotFound)
+
+	api := router.Group("/api/v1")
+	api.GET("/search", h.SearchArticles)
+	api.GET("/categories", h.GetCategories)
+	api.GET("/categories/:slug", h.GetArticlesByCategory)
+	api.GET("/articles", h.GetArticles)
+	api.GET("/articles/:id", h.GetArticleByID)
+	api.GET("/articles/by-slug/:slug", h.GetArticleBySlug)
+	api.GET("/articles/:id/sections/:anchor", h.GetArticleSection)
+
+	admin := router.Group("/api/v1/admin")
+	admin.POST("/articles", h.CreateArticle)
+	admin.PUT("/articles/:id", h.UpdateArticle)
+	admin.PATCH("/articles/:id", h.PatchArticle)
+	admin.DELETE("/articles/:id", h.DeleteArticle)
+	admin.GET("/categories/:id", h.GetCategoryByID)
+	admin.POST("/categories", h.CreateCategory)
+	admin.PUT("/categories/:id", h.UpdateCategory)
+	admin.PATCH("/categories/:id", h.PatchCategory)
+	admin.DELETE("/categories/:id", h.DeleteCategory)
+	return router
+}
+
+// serve sends a request to router; headers are given as name/value pairs.
+func serve(router http.Handler, method, target, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decode unmarshals the response body into v.
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body, err)
+	}
+}
+
+// problemCode returns the code of a problem response.
+func problemCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, ProblemContentType) {
+		t.Fatalf("got Content-Type %q, want %s", ct, ProblemContentType)
+	}
+	var p Problem
+	decode(t, w, &p)
+	return p.Code
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jalikey/zysj-backend/internal/migrate"
+)
+
+// healthCheckTimeout bounds each readiness check so that a hung database
+// fails the probe instead of blocking it.
+const healthCheckTimeout = 2 * time.Second
+
+// CheckResult is the outcome of a single readiness check.
+type CheckResult struct {
+	Status    string                 `json:"status"` // "ok" or "fail"
+	LatencyMS float64                `json:"latency_ms"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// HealthResponse is the body returned by the health endpoints.
+type HealthResponse struct {
+	Status string                 `json:"status"` // "ok" or "unavailable"
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Healthz reports that the process is alive and serving requests. It does
+// not touch any dependency, so a database outage doesn't get the container
+// restarted.
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// Readyz returns a handler that reports whether the instance can serve
+// traffic: the database answers through the pool, the schema is migrated
+// to the version this binary expects and the pool has connections to
+// spare. It responds 503 when any check fails. Unless detailed is set, only
+// the status of each check is reported: errors and pool statistics are for
+// the internal port, not for anyone who can reach the API.
+func Readyz(pool *pgxpool.Pool, detailed bool) gin.HandlerFunc {
+	return readiness(map[string]readinessCheck{
+		"database": func(ctx context.Context) (map[string]interface{}, error) {
+			return nil, pool.Ping(ctx)
+		},
+		"migrations": func(ctx context.Context) (map[string]interface{}, error) {
+			return checkMigrations(ctx, pool)
+		},
+		"pool": func(ctx context.Context) (map[string]interface{}, error) {
+			return checkPool(pool)
+		},
+	}, detailed)
+}
+
+// readinessCheck is a single check run by Readyz. It returns details about
+// what it checked, which are reported even when it fails.
+type readinessCheck func(ctx context.Context) (map[string]interface{}, error)
+
+// readiness returns a handler that runs checks and reports their results,
+// as described for Readyz.
+func readiness(checks map[string]readinessCheck, detailed bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resp := HealthResponse{Status: "ok", Checks: make(map[string]CheckResult, len(checks))}
+		for name, check := range checks {
+			result := runCheck(c.Request.Context(), check)
+			if result.Status != "ok" {
+				resp.Status = "unavailable"
+			}
+			if !detailed {
+				result.Error, result.Details = "", nil
+			}
+			resp.Checks[name] = result
+		}
+
+		status := http.StatusOK
+		if resp.Status != "ok" {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, resp)
+	}
+}
+
+// runCheck runs check under healthCheckTimeout and times it.
+func runCheck(ctx context.Context, check readinessCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check(ctx)
+	result := CheckResult{
+		Status:    "ok",
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   details,
+	}
+	if err != nil {
+		result.Status = "fail"
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// checkMigrations fails while the database is behind the newest embedded
+// migration. A database ahead of it is accepted, since during a rolling
+// deploy the old instances keep serving after the new ones migrated.
+func checkMigrations(ctx context.Context, pool *pgxpool.Pool) (map[string]interface{}, error) {
+	expected, err := migrate.LatestVersion()
+	if err != nil {
+		return nil, err
+	}
+	current, err := migrate.CurrentVersion(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	details := map[string]interface{}{"current": current, "expected": expected}
+	if current < expected {
+		return details, fmt.Errorf("database is at migration %d, expected %d", current, expected)
+	}
+	return details, nil
+}
+
+// checkPool fails when every connection the pool may open is in use.
+func checkPool(pool *pgxpool.Pool) (map[string]interface{}, error) {
+	stat := pool.Stat()
+	details := map[string]interface{}{
+		"acquired": stat.AcquiredConns(),
+		"idle":     stat.IdleConns(),
+		"total":    stat.TotalConns(),
+		"max":      stat.MaxConns(),
+	}
+	if stat.AcquiredConns() >= stat.MaxConns() {
+		return details, fmt.Errorf("all %d connections are in use", stat.MaxConns())
+	}
+	return details, nil
+}
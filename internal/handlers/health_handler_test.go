@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestHealthz(t *testing.T) {
+	router := gin.New()
+	router.GET("/healthz", Healthz)
+	w := serve(router, http.MethodGet, "/healthz", "")
+	var resp HealthResponse
+	decode(t, w, &resp)
+	if w.Code != http.StatusOK || resp.Status != "ok" || resp.Checks != nil {
+		t.Errorf("got status %d and %+v", w.Code, resp)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	ok := func(context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"current": 3}, nil
+	}
+	failing := func(context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"current": 2}, errors.New("behind")
+	}
+
+	tests := []struct {
+		name        string
+		checks      map[string]readinessCheck
+		detailed    bool
+		wantStatus  int
+		wantBody    string
+		wantError   string
+		wantDetails bool
+	}{
+		{"all ok", map[string]readinessCheck{"a": ok, "b": ok}, false, http.StatusOK, "ok", "", false},
+		{"all ok, detailed", map[string]readinessCheck{"a": ok, "b": ok}, true, http.StatusOK, "ok", "", true},
+		{"one failing", map[string]readinessCheck{"a": ok, "b": failing}, false, http.StatusServiceUnavailable, "unavailable", "", false},
+		{"one failing, detailed", map[string]readinessCheck{"a": ok, "b": failing}, true, http.StatusServiceUnavailable, "unavailable", "behind", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/readyz", readiness(tt.checks, tt.detailed))
+			w := serve(router, http.MethodGet, "/readyz", "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			var resp HealthResponse
+			decode(t, w, &resp)
+			if resp.Status != tt.wantBody || len(resp.Checks) != len(tt.checks) {
+				t.Fatalf("got %+v", resp)
+			}
+			b := resp.Checks["b"]
+			if b.Error != tt.wantError {
+				t.Errorf("got error %q, want %q", b.Error, tt.wantError)
+			}
+			if (b.Details != nil) != tt.wantDetails {
+				t.Errorf("got details %v, want details %v", b.Details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+// TestReadyzUnreachable runs the real checks against a pool whose database
+// can't be reached.
+func TestReadyzUnreachable(t *testing.T) {
+	pool, err := pgxpool.New(context.Background(), "postgres://zysj@127.0.0.1:1/zysj?connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for _, detailed := range []bool{false, true} {
+		router := gin.New()
+		router.GET("/readyz", Readyz(pool, detailed))
+		w := serve(router, http.MethodGet, "/readyz", "")
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("detailed=%v: got status %d, want 503", detailed, w.Code)
+		}
+		var resp HealthResponse
+		decode(t, w, &resp)
+
+		want := map[string]string{"database": "fail", "migrations": "fail", "pool": "ok"}
+		for name, status := range want {
+			got := resp.Checks[name]
+			if got.Status != status {
+				t.Errorf("detailed=%v: %s is %q, want %q", detailed, name, got.Status, status)
+			}
+			if detailed != (got.Error != "" || got.Details != nil) {
+				t.Errorf("detailed=%v: %s reported error %q and details %v", detailed, name, got.Error, got.Details)
+			}
+		}
+	}
+}
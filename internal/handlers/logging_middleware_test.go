@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{"uuid", "3f2b8c1e-6d4a-4b8e-9c7a-1e2f3a4b5c6d", true},
+		{"hex", "0123456789abcdef", true},
+		{"punctuation", "trace:abc/123_x.y", true},
+		{"single character", "a", true},
+		{"max length", strings.Repeat("a", maxRequestIDLength), true},
+		{"empty", "", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+		{"space", "abc def", false},
+		{"newline", "abc\nINFO forged log line", false},
+		{"tab", "abc\tdef", false},
+		{"delete", "abc\x7f", false},
+		{"non-ascii", "请求-1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validRequestID(tt.id); got != tt.want {
+				t.Errorf("validRequestID(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := serve(router, http.MethodGet, "/", "", RequestIDHeader, "abc-123")
+	if got := w.Header().Get(RequestIDHeader); got != "abc-123" {
+		t.Errorf("got %q, want the caller's ID echoed", got)
+	}
+
+	w = serve(router, http.MethodGet, "/", "", RequestIDHeader, "bad id")
+	if got := w.Header().Get(RequestIDHeader); got == "bad id" || !validRequestID(got) {
+		t.Errorf("got %q, want a generated ID replacing the invalid one", got)
+	}
+}
@@ -2,41 +2,137 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
 	"github.com/jalikey/zysj-backend/internal/auth"
+	"github.com/jalikey/zysj-backend/internal/logging"
+	"github.com/jalikey/zysj-backend/internal/models"
+	"github.com/jalikey/zysj-backend/internal/repository"
 )
 
-// AuthMiddleware checks for a valid JWT in the Authorization header.
-func AuthMiddleware() gin.HandlerFunc {
+// apiKeyContextKey holds the models.APIKey of requests authenticated with
+// an API key.
+const apiKeyContextKey = "api_key"
+
+// AuthMiddleware checks for a valid JWT or API key in the Authorization
+// header. API keys must be active; their last use is recorded in keys.
+func AuthMiddleware(keys repository.APIKeyStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+			fail(c, unauthorized("Authorization header is required"))
 			return
 		}
 
 		// The header should be in the format "Bearer <token>"
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token}"})
+			fail(c, unauthorized("Authorization header format must be Bearer {token}"))
 			return
 		}
 
 		tokenString := parts[1]
+		if auth.IsAPIKey(tokenString) {
+			authenticateAPIKey(c, keys, tokenString)
+			return
+		}
+
 		token, err := auth.ValidateJWT(tokenString)
 
 		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			fail(c, unauthorized("Invalid or expired token"))
 			return
 		}
 
-		// You can also set user info in the context for later use in handlers
-		// claims := token.Claims.(jwt.MapClaims)
-		// c.Set("username", claims["username"])
+		// Record who is making the request for handlers and log lines
+		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			if username, ok := claims["username"].(string); ok {
+				c.Set("username", username)
+				c.Request = c.Request.WithContext(logging.WithAttrs(c.Request.Context(), slog.String("user", username)))
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// authenticateAPIKey continues the handler chain if secret is an active
+// API key.
+func authenticateAPIKey(c *gin.Context, keys repository.APIKeyStore, secret string) {
+	ctx := c.Request.Context()
+	key, err := keys.GetAPIKeyByHash(ctx, auth.HashAPIKey(secret))
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && !key.Active(time.Now())) {
+		fail(c, unauthorized("Invalid, expired or revoked API key"))
+		return
+	}
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	if err := keys.TouchAPIKey(ctx, key.ID); err != nil {
+		slog.WarnContext(ctx, "Failed to record API key use", "api_key", key.Prefix, "error", err)
+	}
+	c.Set(apiKeyContextKey, key)
+	c.Request = c.Request.WithContext(logging.WithAttrs(ctx, slog.String("api_key", key.Prefix)))
+	c.Next()
+}
+
+// requestAPIKey returns the API key the request was authenticated with,
+// if any.
+func requestAPIKey(c *gin.Context) (models.APIKey, bool) {
+	v, ok := c.Get(apiKeyContextKey)
+	if !ok {
+		return models.APIKey{}, false
+	}
+	key, ok := v.(models.APIKey)
+	return key, ok
+}
 
+// RequireScope rejects requests made with an API key that wasn't granted
+// scope. Users logged in with a JWT have every scope.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key, ok := requestAPIKey(c); ok && !key.HasScope(scope) {
+			fail(c, &APIError{Status: http.StatusForbidden, Code: "insufficient_scope", Detail: "The API key lacks the " + scope + " scope"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireUser rejects requests made with an API key, for endpoints that
+// only a logged in user may call.
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requestAPIKey(c); ok {
+			fail(c, &APIError{Status: http.StatusForbidden, Code: "user_required", Detail: "This endpoint can't be called with an API key"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Timeout bounds the request context with the given deadline, so that the
+// database queries made by the handlers are cancelled once it passes. A
+// zero duration leaves the context unbounded.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
-}
\ No newline at end of file
+}
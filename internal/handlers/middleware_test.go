@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jalikey/zysj-backend/internal/auth"
+	"github.com/jalikey/zysj-backend/internal/config"
+	"github.com/jalikey/zysj-backend/internal/models"
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+// addAPIKey stores a key with the given scopes and returns its secret.
+func addAPIKey(t *testing.T, store *repository.MemoryStore, scopes []string, expiresAt *time.Time, revoked bool) string {
+	t.Helper()
+	secret, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	id, err := store.CreateAPIKey(ctx, models.APIKey{Name: "test", Prefix: prefix, KeyHash: hash, Scopes: scopes, ExpiresAt: expiresAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked {
+		if err := store.RevokeAPIKey(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return secret
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	auth.Configure(config.AuthConfig{JWTSecret: "test-secret", TokenTTL: config.Duration(time.Hour)})
+	store := repository.NewMemoryStore()
+
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	router := gin.New()
+	router.Use(ErrorHandler())
+	admin := router.Group("/admin", AuthMiddleware(store))
+	admin.GET("/articles", RequireScope(auth.ScopeRead), ok)
+	admin.POST("/articles", RequireScope(auth.ScopeArticlesWrite), ok)
+	admin.POST("/categories", RequireScope(auth.ScopeCategoriesWrite), ok)
+	admin.GET("/api-keys", RequireUser(), ok)
+
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	readKey := addAPIKey(t, store, []string{auth.ScopeRead}, nil, false)
+	writerKey := addAPIKey(t, store, []string{auth.ScopeRead, auth.ScopeArticlesWrite}, &future, false)
+	expiredKey := addAPIKey(t, store, []string{auth.ScopeRead}, &past, false)
+	revokedKey := addAPIKey(t, store, []string{auth.ScopeRead}, nil, true)
+	token, err := auth.GenerateJWT("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		target        string
+		authorization string
+		wantStatus    int
+		wantCode      string
+	}{
+		{"no header", http.MethodGet, "/admin/articles", "", http.StatusUnauthorized, "unauthorized"},
+		{"not bearer", http.MethodGet, "/admin/articles", "Basic " + readKey, http.StatusUnauthorized, "unauthorized"},
+		{"bad jwt", http.MethodGet, "/admin/articles", "Bearer not.a.jwt", http.StatusUnauthorized, "unauthorized"},
+		{"unknown key", http.MethodGet, "/admin/articles", "Bearer " + auth.APIKeyPrefix + "0000", http.StatusUnauthorized, "unauthorized"},
+		{"expired key", http.MethodGet, "/admin/articles", "Bearer " + expiredKey, http.StatusUnauthorized, "unauthorized"},
+		{"revoked key", http.MethodGet, "/admin/articles", "Bearer " + revokedKey, http.StatusUnauthorized, "unauthorized"},
+
+		{"key with scope", http.MethodGet, "/admin/articles", "Bearer " + readKey, http.StatusNoContent, ""},
+		{"key without scope", http.MethodPost, "/admin/articles", "Bearer " + readKey, http.StatusForbidden, "insufficient_scope"},
+		{"key with write scope", http.MethodPost, "/admin/articles", "Bearer " + writerKey, http.StatusNoContent, ""},
+		{"key with other write scope", http.MethodPost, "/admin/categories", "Bearer " + writerKey, http.StatusForbidden, "insufficient_scope"},
+		{"key managing keys", http.MethodGet, "/admin/api-keys", "Bearer " + writerKey, http.StatusForbidden, "user_required"},
+
+		{"user reading", http.MethodGet, "/admin/articles", "Bearer " + token, http.StatusNoContent, ""},
+		{"user writing articles", http.MethodPost, "/admin/articles", "Bearer " + token, http.StatusNoContent, ""},
+		{"user writing categories", http.MethodPost, "/admin/categories", "Bearer " + token, http.StatusNoContent, ""},
+		{"user managing keys", http.MethodGet, "/admin/api-keys", "Bearer " + token, http.StatusNoContent, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var headers []string
+			if tt.authorization != "" {
+				headers = []string{"Authorization", tt.authorization}
+			}
+			w := serve(router, tt.method, tt.target, "", headers...)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantCode != "" {
+				if code := problemCode(t, w); code != tt.wantCode {
+					t.Errorf("got code %q, want %q", code, tt.wantCode)
+				}
+			}
+		})
+	}
+
+	keys, err := store.GetAllAPIKeys(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range keys {
+		if used := k.LastUsedAt != nil; used != k.Active(time.Now()) {
+			t.Errorf("key %d: recorded use %v, want it only for active keys", k.ID, used)
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		work         time.Duration
+		wantStatus   int
+		wantDeadline bool
+	}{
+		{"within budget", time.Second, 0, http.StatusNoContent, true},
+		{"over budget", 10 * time.Millisecond, time.Second, http.StatusGatewayTimeout, true},
+		{"disabled", 0, 0, http.StatusNoContent, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler(), Timeout(tt.timeout))
+			router.GET("/", func(c *gin.Context) {
+				ctx := c.Request.Context()
+				if _, ok := ctx.Deadline(); ok != tt.wantDeadline {
+					t.Errorf("request has deadline = %v, want %v", ok, tt.wantDeadline)
+				}
+				select {
+				case <-time.After(tt.work):
+					c.Status(http.StatusNoContent)
+				case <-ctx.Done():
+					fail(c, ctx.Err())
+				}
+			})
+
+			w := serve(router, http.MethodGet, "/", "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// MergePatchContentType is the media type of JSON Merge Patch (RFC 7396)
+// documents, which PATCH endpoints accept alongside plain JSON.
+const MergePatchContentType = "application/merge-patch+json"
+
+// bindMergePatch applies the request body, a JSON Merge Patch, to the JSON
+// form of current, then decodes the result into dst and validates it like
+// a full payload. Fields missing from the patch keep their current value
+// and fields set to null are cleared.
+func bindMergePatch(c *gin.Context, current, dst interface{}) error {
+	if ct := c.ContentType(); ct != MergePatchContentType && ct != binding.MIMEJSON {
+		return &APIError{
+			Status: http.StatusUnsupportedMediaType,
+			Code:   "unsupported_media_type",
+			Detail: "PATCH requests must be sent as " + MergePatchContentType,
+		}
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return invalidPayload(io.EOF)
+	}
+	var patch interface{}
+	if err := json.Unmarshal(body, &patch); err != nil {
+		return invalidPayload(err)
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return err
+	}
+	merged, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(merged, dst); err != nil {
+		return invalidPayload(err)
+	}
+	if err := binding.Validator.ValidateStruct(dst); err != nil {
+		return invalidPayload(err)
+	}
+	return nil
+}
+
+// mergePatch applies patch to target following RFC 7396: objects are merged
+// recursively, null removes a member and any other value replaces it.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jalikey/zysj-backend/internal/metrics"
+	"github.com/jalikey/zysj-backend/internal/ratelimit"
+)
+
+// RateLimit throttles the requests of each client to the route group with
+// a token bucket sized by limit. Clients are identified by user or API key
+// once AuthMiddleware has run, by IP otherwise. The IP is only taken from
+// X-Forwarded-For behind the proxies passed to SetTrustedProxies; the router
+// must be configured with them, as gin otherwise trusts every peer and
+// clients could pick a fresh bucket per request. Every response carries the
+// RateLimit-* headers; refused requests get a 429 with Retry-After. If the
+// limiter fails, requests are let through.
+func RateLimit(group string, limiter ratelimit.Limiter, limit ratelimit.Limit) gin.HandlerFunc {
+	if !limit.Enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+	policy := fmt.Sprintf("%d;w=%d", limit.Burst, ceilSeconds(time.Duration(float64(limit.Burst)/limit.Rate*float64(time.Second))))
+
+	return func(c *gin.Context) {
+		client := "ip:" + c.ClientIP()
+		if username := c.GetString("username"); username != "" {
+			client = "user:" + username
+		} else if key, ok := requestAPIKey(c); ok {
+			client = "key:" + strconv.FormatInt(key.ID, 10)
+		}
+
+		res, err := limiter.Allow(c.Request.Context(), group+":"+client, limit)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "Rate limiter failed, letting request through", "group", group, "error", err)
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Set("RateLimit-Policy", policy)
+		h.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+		if !res.Allowed {
+			retryAfter := ceilSeconds(res.RetryAfter)
+			h.Set("Retry-After", strconv.Itoa(retryAfter))
+			metrics.RateLimited(group)
+			fail(c, &APIError{
+				Status: http.StatusTooManyRequests,
+				Code:   "rate_limited",
+				Detail: fmt.Sprintf("Too many requests, retry in %d seconds", retryAfter),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ceilSeconds rounds d up to whole seconds, as used by the rate limit
+// headers.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jalikey/zysj-backend/internal/ratelimit"
+)
+
+// newRateLimitedRouter throttles GET / to one request per client, trusting
+// forwarded client IPs from proxies only.
+func newRateLimitedRouter(t *testing.T, proxies []string) *gin.Engine {
+	t.Helper()
+	router := gin.New()
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		t.Fatal(err)
+	}
+	router.Use(ErrorHandler())
+	router.GET("/", RateLimit("test", ratelimit.NewMemory(), ratelimit.Limit{Rate: 0.001, Burst: 1}), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func TestRateLimit(t *testing.T) {
+	router := newRateLimitedRouter(t, nil)
+
+	w := serve(router, http.MethodGet, "/", "")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("first request: got status %d", w.Code)
+	}
+	for _, header := range []string{"RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"} {
+		if w.Header().Get(header) == "" {
+			t.Errorf("missing %s header", header)
+		}
+	}
+
+	w = serve(router, http.MethodGet, "/", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: got status %d, want 429", w.Code)
+	}
+	if code := problemCode(t, w); code != "rate_limited" {
+		t.Errorf("got code %q, want rate_limited", code)
+	}
+	if retry, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || retry <= 0 {
+		t.Errorf("got Retry-After %q, want a positive number of seconds", w.Header().Get("Retry-After"))
+	}
+}
+
+// Clients must not escape their bucket by sending X-Forwarded-For, unless
+// the request comes through a trusted proxy. httptest requests come from
+// 192.0.2.1.
+func TestRateLimitForwardedFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		proxies    []string
+		wantStatus int
+	}{
+		{"untrusted peer shares its bucket", nil, http.StatusTooManyRequests},
+		{"other proxy shares its bucket", []string{"10.0.0.0/8"}, http.StatusTooManyRequests},
+		{"trusted proxy forwards separate clients", []string{"192.0.2.1"}, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newRateLimitedRouter(t, tt.proxies)
+			if w := serve(router, http.MethodGet, "/", "", "X-Forwarded-For", "203.0.113.1"); w.Code != http.StatusNoContent {
+				t.Fatalf("first request: got status %d", w.Code)
+			}
+			w := serve(router, http.MethodGet, "/", "", "X-Forwarded-For", "203.0.113.2", "X-Real-IP", "203.0.113.2")
+			if w.Code != tt.wantStatus {
+				t.Errorf("request with another forwarded IP: got status %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
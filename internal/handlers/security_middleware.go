@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jalikey/zysj-backend/internal/config"
+)
+
+// corsExposedHeaders are the response headers, beyond the CORS-safelisted
+// ones, that frontends need to read: validators for conditional requests,
+// the location of created resources, rate limits and the request ID to
+// quote in bug reports.
+var corsExposedHeaders = strings.Join([]string{
+	"ETag", "Location", "Retry-After",
+	"RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset",
+	RequestIDHeader,
+}, ", ")
+
+// CORS lets browsers on the configured origins call the API. Preflight
+// requests are answered directly, whether or not a route matches them.
+// Requests from other origins get no CORS headers, so browsers refuse to
+// hand them the response.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	anyOrigin := false
+	origins := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
+		}
+		origins[strings.ToLower(origin)] = true
+	}
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(time.Duration(cfg.MaxAge).Seconds()))
+	// Unless every origin gets "*", the response depends on the Origin
+	// header, including whether it was sent at all; shared caches must not
+	// hand a response made for one origin to another
+	varies := len(origins) > 0 && (!anyOrigin || cfg.AllowCredentials)
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		if varies {
+			h.Add("Vary", "Origin")
+		}
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !anyOrigin && !origins[strings.ToLower(origin)] {
+			if preflight {
+				c.AbortWithStatus(http.StatusNoContent)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Allow-Credentials", "true")
+		} else if anyOrigin {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if preflight {
+			h.Set("Access-Control-Allow-Methods", methods)
+			h.Set("Access-Control-Allow-Headers", headers)
+			h.Set("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		h.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+		c.Next()
+	}
+}
+
+// SecurityHeaders sets the security headers of every response: the
+// configured Content-Security-Policy, X-Frame-Options and
+// Strict-Transport-Security, along with X-Content-Type-Options and
+// Referrer-Policy.
+func SecurityHeaders(cfg config.SecurityConfig) gin.HandlerFunc {
+	var hsts string
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(time.Duration(cfg.HSTSMaxAge).Seconds()))
+	}
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("Referrer-Policy", "no-referrer")
+		if cfg.ContentSecurityPolicy != "" {
+			h.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+		if cfg.FrameOptions != "" {
+			h.Set("X-Frame-Options", cfg.FrameOptions)
+		}
+		if hsts != "" {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
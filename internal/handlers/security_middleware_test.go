@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jalikey/zysj-backend/internal/config"
+)
+
+func newCORSRouter(origins []string, credentials bool) *gin.Engine {
+	cfg := config.Default().HTTP.CORS
+	cfg.AllowedOrigins = origins
+	cfg.AllowCredentials = credentials
+
+	router := gin.New()
+	router.Use(CORS(cfg))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return router
+}
+
+func TestCORS(t *testing.T) {
+	const app = "https://app.example.com"
+
+	tests := []struct {
+		name        string
+		origins     []string
+		credentials bool
+		origin      string
+		wantAllow   string
+		wantVary    bool
+	}{
+		{"listed origin", []string{app}, false, app, app, true},
+		{"listed origin, case-insensitive", []string{app}, false, "https://APP.example.com", "https://APP.example.com", true},
+		{"unlisted origin", []string{app}, false, "https://evil.example.com", "", true},
+		{"no origin", []string{app}, false, "", "", true},
+		{"credentials", []string{app}, true, app, app, true},
+		{"any origin", []string{"*"}, false, app, "*", false},
+		{"any origin, no origin", []string{"*"}, false, "", "", false},
+		{"disabled", nil, false, app, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newCORSRouter(tt.origins, tt.credentials)
+			var headers []string
+			if tt.origin != "" {
+				headers = []string{"Origin", tt.origin}
+			}
+			w := serve(router, http.MethodGet, "/", "", headers...)
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("got status %d", w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantAllow {
+				t.Errorf("got Access-Control-Allow-Origin %q, want %q", got, tt.wantAllow)
+			}
+			if got := w.Header().Get("Vary") == "Origin"; got != tt.wantVary {
+				t.Errorf("got Vary %q, want Origin: %v", w.Header().Get("Vary"), tt.wantVary)
+			}
+			if credentials := w.Header().Get("Access-Control-Allow-Credentials"); (credentials == "true") != (tt.credentials && tt.wantAllow != "") {
+				t.Errorf("got Access-Control-Allow-Credentials %q", credentials)
+			}
+		})
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	const app = "https://app.example.com"
+	router := newCORSRouter([]string{app}, false)
+
+	tests := []struct {
+		name        string
+		origin      string
+		wantMethods bool
+	}{
+		{"allowed", app, true},
+		{"refused", "https://evil.example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodOptions, "/", "", "Origin", tt.origin, "Access-Control-Request-Method", "PUT")
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("got status %d, want 204", w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods") != ""; got != tt.wantMethods {
+				t.Errorf("got Access-Control-Allow-Methods %q", w.Header().Get("Access-Control-Allow-Methods"))
+			}
+			if tt.wantMethods && w.Header().Get("Access-Control-Max-Age") != "600" {
+				t.Errorf("got Access-Control-Max-Age %q, want 600", w.Header().Get("Access-Control-Max-Age"))
+			}
+		})
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.SecurityConfig
+		wantHSTS string
+		wantCSP  string
+	}{
+		{"defaults", config.Default().HTTP.Security, "max-age=15552000", "default-src 'none'; frame-ancestors 'none'"},
+		{"disabled", config.SecurityConfig{}, "", ""},
+		{"short hsts", config.SecurityConfig{HSTSMaxAge: config.Duration(time.Hour)}, "max-age=3600", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(SecurityHeaders(tt.cfg))
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+			w := serve(router, http.MethodGet, "/", "")
+			if got := w.Header().Get("Strict-Transport-Security"); got != tt.wantHSTS {
+				t.Errorf("got Strict-Transport-Security %q, want %q", got, tt.wantHSTS)
+			}
+			if got := w.Header().Get("Content-Security-Policy"); got != tt.wantCSP {
+				t.Errorf("got Content-Security-Policy %q, want %q", got, tt.wantCSP)
+			}
+			if got := w.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+				t.Errorf("got X-Content-Type-Options %q, want nosniff", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jalikey/zysj-backend/internal/content"
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+// Articles and categories are addressed by slug in public URLs. Admins may
+// set one explicitly; otherwise it is generated from the title or name on
+// creation and kept on later updates, so that URLs stay stable. Renaming a
+// slug keeps the old one as a redirect.
+
+// maxSlugAttempts bounds how often a record is created again after the
+// slug generated for it was taken by a concurrent request.
+const maxSlugAttempts = 3
+
+// createWithSlug creates a record through create, with the requested slug
+// or, without one, a slug generated from text and made unique by unique.
+// Another request can take a generated slug between the uniqueness check
+// and the insert; creation is then retried with a fresh slug. A requested
+// slug that is taken fails with the conflict.
+func createWithSlug(ctx context.Context, requested, text string, unique func(context.Context, string, int64) (string, error), create func(slug string) (int64, error)) (int64, error) {
+	if requested != "" {
+		return create(requested)
+	}
+	for attempt := 1; ; attempt++ {
+		slug, err := unique(ctx, content.Slugify(text), 0)
+		if err != nil {
+			return 0, err
+		}
+		id, err := create(slug)
+		if attempt < maxSlugAttempts && slugConflict(err) {
+			continue
+		}
+		return id, err
+	}
+}
+
+// slugConflict reports whether err is a unique violation on the slug.
+func slugConflict(err error) bool {
+	var repoErr *repository.Error
+	return errors.As(err, &repoErr) && repoErr.Kind == repository.ErrConflict && repoErr.Field == "slug"
+}
+
+// slugRedirect handles a lookup of slug that failed with lookupErr. When
+// the slug used to belong to a record that has since been renamed, it
+// answers with a permanent redirect to the record's current URL and returns
+// nil; otherwise it returns the error to fail with.
+func slugRedirect(c *gin.Context, slug string, lookupErr error, current func(context.Context, string) (string, error)) error {
+	if !errors.Is(lookupErr, repository.ErrNotFound) {
+		return lookupErr
+	}
+	target, err := current(c.Request.Context(), slug)
+	if errors.Is(err, repository.ErrNotFound) {
+		return lookupErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// The slug is the last path segment of every route looked up by slug
+	u := *c.Request.URL
+	u.Path = path.Join(path.Dir(u.Path), target)
+	u.RawPath = ""
+	c.Redirect(http.StatusMovedPermanently, u.RequestURI())
+	return nil
+}
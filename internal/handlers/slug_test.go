@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jalikey/zysj-backend/internal/models"
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+// racingStore lets another writer take a slug between the uniqueness check
+// and the insert, the given number of times.
+type racingStore struct {
+	*repository.MemoryStore
+	races int
+}
+
+func (s *racingStore) CreateArticle(ctx context.Context, article models.Article) (int64, error) {
+	if s.races > 0 {
+		s.races--
+		if _, err := s.MemoryStore.CreateArticle(ctx, models.Article{Title: "Rival", Slug: article.Slug, Content: "text", ContentFormat: "plain"}); err != nil {
+			return 0, err
+		}
+	}
+	return s.MemoryStore.CreateArticle(ctx, article)
+}
+
+func TestCreateArticleSlugRace(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		races      int
+		wantStatus int
+		wantSlug   string
+	}{
+		{"no race", `{"title":"Herbs","content":"text"}`, 0, http.StatusCreated, "herbs"},
+		{"generated slug taken once", `{"title":"Herbs","content":"text"}`, 1, http.StatusCreated, "herbs-2"},
+		{"generated slug taken twice", `{"title":"Herbs","content":"text"}`, 2, http.StatusCreated, "herbs-3"},
+		{"gives up", `{"title":"Herbs","content":"text"}`, maxSlugAttempts, http.StatusConflict, ""},
+		{"requested slug taken", `{"title":"Herbs","slug":"herbs","content":"text"}`, 1, http.StatusConflict, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := repository.NewMemoryStore()
+			store := &racingStore{MemoryStore: memory, races: tt.races}
+			h := New(store, memory, memory, memory)
+			router, _ := newTestRouter()
+			router.POST("/race", h.CreateArticle)
+
+			w := serve(router, http.MethodPost, "/race", tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantSlug == "" {
+				return
+			}
+			var article models.Article
+			decode(t, w, &article)
+			if article.Slug != tt.wantSlug {
+				t.Errorf("got slug %q, want %q", article.Slug, tt.wantSlug)
+			}
+		})
+	}
+}
@@ -1,11 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jalikey/zysj-backend/internal/auth"
-	"github.com/jalikey/zysj-backend/internal/models"
+	"github.com/jalikey/zysj-backend/internal/metrics"
 	"github.com/jalikey/zysj-backend/internal/repository"
 )
 
@@ -14,30 +15,41 @@ type LoginPayload struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// errInvalidCredentials is returned for both unknown users and wrong
+// passwords, so that the response doesn't reveal which usernames exist.
+var errInvalidCredentials = &APIError{Status: http.StatusUnauthorized, Code: "invalid_credentials", Detail: "Invalid username or password"}
+
 // Login handles user authentication and returns a JWT.
-func Login(c *gin.Context) {
+func (h *Handler) Login(c *gin.Context) {
 	var payload LoginPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		fail(c, invalidPayload(err))
 		return
 	}
 
-	user, err := repository.GetUserByUsername(payload.Username)
+	user, err := h.Users.GetUserByUsername(c.Request.Context(), payload.Username)
+	if errors.Is(err, repository.ErrNotFound) {
+		metrics.LoginFailed()
+		fail(c, errInvalidCredentials)
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		fail(c, err)
 		return
 	}
 
 	if !auth.CheckPasswordHash(payload.Password, user.PasswordHash) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		metrics.LoginFailed()
+		fail(c, errInvalidCredentials)
 		return
 	}
 
 	token, err := auth.GenerateJWT(user.Username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
+		fail(c, err)
 		return
 	}
 
+	metrics.LoginSucceeded()
 	c.JSON(http.StatusOK, gin.H{"token": token})
 }
\ No newline at end of file
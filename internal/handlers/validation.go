@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/jalikey/zysj-backend/internal/repository"
+)
+
+// slugPattern accepts lowercase ASCII words separated by single hyphens,
+// e.g. "tcm-basics".
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// maxCategoryDepth bounds the walk up the category tree when checking a
+// new parent, in case the stored tree already contains a cycle.
+const maxCategoryDepth = 64
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// Report payload fields by their JSON names rather than Go field names
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+
+	_ = v.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		return slugPattern.MatchString(fl.Field().String())
+	})
+	_ = v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+}
+
+// validationMessage describes a failed validation rule in words.
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "notblank":
+		return "must not be blank"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "max":
+		return "must be at most " + fe.Param() + " characters long"
+	case "min":
+		if fe.Param() == "1" {
+			return "must not be empty"
+		}
+		return "must have at least " + fe.Param() + " entries"
+	case "gte":
+		return "must be greater than or equal to " + fe.Param()
+	case "slug":
+		return "must contain only lowercase letters, digits and single hyphens, e.g. tcm-basics"
+	}
+	return "failed the " + fe.Tag() + " rule"
+}
+
+// invalidField returns a 422 error for a single field.
+func invalidField(field, code, message string) *APIError {
+	return &APIError{
+		Status: http.StatusUnprocessableEntity,
+		Code:   "validation_failed",
+		Detail: "The request body failed validation",
+		Fields: []FieldError{{Field: field, Code: code, Message: message}},
+	}
+}
+
+// checkCategoryExists fails with a field error when id, given in field, is
+// set but refers to no category.
+func (h *Handler) checkCategoryExists(ctx context.Context, field string, id int64) error {
+	if id == 0 {
+		return nil
+	}
+	_, err := h.Categories.GetCategoryByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return invalidField(field, "invalid_reference", fmt.Sprintf("category %d does not exist", id))
+	}
+	return err
+}
+
+// checkCategoryParent fails when making parentID the parent of category id
+// would reference a missing category or create a cycle.
+func (h *Handler) checkCategoryParent(ctx context.Context, id, parentID int64) error {
+	if err := h.checkCategoryExists(ctx, "parent_id", parentID); err != nil || parentID == 0 || id == 0 {
+		return err
+	}
+
+	cycle := invalidField("parent_id", "cycle", "a category cannot be nested inside itself or its subcategories")
+	for ancestor, depth := parentID, 0; ancestor != 0; depth++ {
+		if ancestor == id || depth >= maxCategoryDepth {
+			return cycle
+		}
+		category, err := h.Categories.GetCategoryByID(ctx, ancestor)
+		if err != nil {
+			return err
+		}
+		ancestor = 0
+		if category.ParentID.Valid {
+			ancestor = category.ParentID.Int64
+		}
+	}
+	return nil
+}
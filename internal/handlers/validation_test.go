@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPayloadValidation(t *testing.T) {
+	long := strings.Repeat("x", 256)
+
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantErrors []FieldError
+	}{
+		{
+			name:       "empty body",
+			target:     "/api/v1/admin/articles",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "empty_body",
+		},
+		{
+			name:       "malformed body",
+			target:     "/api/v1/admin/articles",
+			body:       `{"title":`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "malformed_body",
+		},
+		{
+			name:       "wrong type",
+			target:     "/api/v1/admin/articles",
+			body:       `{"title":"x","content":"text","category_id":"one"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_type",
+			wantErrors: []FieldError{{Field: "category_id", Code: "type", Message: "must be a int64"}},
+		},
+		{
+			name:       "every failed field",
+			target:     "/api/v1/admin/articles",
+			body:       `{"slug":"Bad Slug","content":" ","content_format":"rtf","category_id":-1,"author":"` + long + `"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "validation_failed",
+			wantErrors: []FieldError{
+				{Field: "title", Code: "required", Message: "is required"},
+				{Field: "slug", Code: "slug", Message: "must contain only lowercase letters, digits and single hyphens, e.g. tcm-basics"},
+				{Field: "content", Code: "notblank", Message: "must not be blank"},
+				{Field: "content_format", Code: "oneof", Message: "must be one of plain, markdown, html"},
+				{Field: "category_id", Code: "gte", Message: "must be greater than or equal to 0"},
+				{Field: "author", Code: "max", Message: "must be at most 255 characters long"},
+			},
+		},
+		{
+			name:       "category",
+			target:     "/api/v1/admin/categories",
+			body:       `{"name":"` + long + `","parent_id":99}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "validation_failed",
+			wantErrors: []FieldError{{Field: "name", Code: "max", Message: "must be at most 255 characters long"}},
+		},
+		{
+			name:       "unknown parent",
+			target:     "/api/v1/admin/categories",
+			body:       `{"name":"Herbs","parent_id":99}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "validation_failed",
+			wantErrors: []FieldError{{Field: "parent_id", Code: "invalid_reference", Message: "category 99 does not exist"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := newTestRouter()
+			w := serve(router, http.MethodPost, tt.target, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			var p Problem
+			decode(t, w, &p)
+			if p.Code != tt.wantCode {
+				t.Errorf("got code %q, want %q", p.Code, tt.wantCode)
+			}
+			if len(p.Errors) != len(tt.wantErrors) {
+				t.Fatalf("got errors %+v, want %+v", p.Errors, tt.wantErrors)
+			}
+			for i, want := range tt.wantErrors {
+				if p.Errors[i] != want {
+					t.Errorf("error %d: got %+v, want %+v", i, p.Errors[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Package metrics defines the Prometheus metrics exported on /metrics.
+//
+// Metrics are registered with the default Prometheus registry, which also
+// carries the Go runtime and process collectors.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "zysj"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by route, method and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to handle HTTP requests, by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time spent in repository functions, by function.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"operation"})
+
+	logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts, by result (succeeded or failed).",
+	}, []string{"result"})
+
+	articleChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "article_changes_total",
+		Help:      "Articles written through the admin API, by action (created, updated or deleted).",
+	}, []string{"action"})
+
+	cacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Read cache lookups, by kind of value and result (hit or miss).",
+	}, []string{"kind", "result"})
+
+	cacheSharedLoads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_shared_loads_total",
+		Help:      "Read cache misses answered by a load already in flight for another request, by kind of value.",
+	}, []string{"kind"})
+
+	rateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Requests refused for exceeding their rate limit, by route group.",
+	}, []string{"group"})
+)
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// Middleware records the count, status and latency of every request. The
+// route label is the registered path pattern (e.g. /api/v1/articles/:id)
+// so that IDs in URLs don't create a series per article.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveQuery records the time spent in a repository function since start.
+func ObserveQuery(operation string, start time.Time) {
+	queryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+// LoginSucceeded counts a successful login.
+func LoginSucceeded() { logins.WithLabelValues("succeeded").Inc() }
+
+// LoginFailed counts a login rejected for bad credentials.
+func LoginFailed() { logins.WithLabelValues("failed").Inc() }
+
+// ArticleChanged counts an article created, updated or deleted.
+func ArticleChanged(action string) { articleChanges.WithLabelValues(action).Inc() }
+
+// CacheHit counts a read cache lookup of kind that found a value.
+func CacheHit(kind string) { cacheLookups.WithLabelValues(kind, "hit").Inc() }
+
+// CacheMiss counts a read cache lookup of kind that had to load the value.
+func CacheMiss(kind string) { cacheLookups.WithLabelValues(kind, "miss").Inc() }
+
+// CacheSharedLoad counts a miss of kind that waited for another request's
+// load instead of querying the database itself.
+func CacheSharedLoad(kind string) { cacheSharedLoads.WithLabelValues(kind).Inc() }
+
+// RateLimited counts a request of group refused by the rate limiter.
+func RateLimited(group string) { rateLimited.WithLabelValues(group).Inc() }
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/articles/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name   string
+		target string
+		route  string
+		status string
+	}{
+		{"by pattern", "/articles/1", "/articles/:id", "200"},
+		{"same pattern", "/articles/2", "/articles/:id", "200"},
+		{"no route", "/missing/3", "unmatched", "404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := httpRequests.WithLabelValues(http.MethodGet, tt.route, tt.status)
+			before := testutil.ToFloat64(counter)
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Errorf("counted %v requests for route %q, want 1", got, tt.route)
+			}
+		})
+	}
+}
+
+func TestPoolCollector(t *testing.T) {
+	// The pool connects lazily, so its statistics can be read without a
+	// database
+	pool, err := pgxpool.New(context.Background(), "postgres://zysj@127.0.0.1:1/zysj?pool_max_conns=7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	collector := newPoolCollector(pool)
+
+	if n := testutil.CollectAndCount(collector); n != 10 {
+		t.Errorf("collected %d metrics, want 10", n)
+	}
+	if problems, err := testutil.CollectAndLint(collector); err != nil || len(problems) > 0 {
+		t.Errorf("lint: %v %v", err, problems)
+	}
+
+	want := `
+# HELP zysj_db_pool_acquired_connections Connections currently checked out of the pool.
+# TYPE zysj_db_pool_acquired_connections gauge
+zysj_db_pool_acquired_connections 0
+# HELP zysj_db_pool_max_connections Maximum size of the pool.
+# TYPE zysj_db_pool_max_connections gauge
+zysj_db_pool_max_connections 7
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want),
+		"zysj_db_pool_acquired_connections", "zysj_db_pool_max_connections"); err != nil {
+		t.Error(err)
+	}
+}
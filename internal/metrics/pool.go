@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector exports pgxpool statistics, read from the pool on every
+// scrape.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquired     *prometheus.Desc
+	idle         *prometheus.Desc
+	constructing *prometheus.Desc
+	total        *prometheus.Desc
+	max          *prometheus.Desc
+	acquires     *prometheus.Desc
+	acquireTime  *prometheus.Desc
+	waits        *prometheus.Desc
+	waitTime     *prometheus.Desc
+	canceled     *prometheus.Desc
+}
+
+// RegisterPool exports the statistics of pool on /metrics.
+func RegisterPool(pool *pgxpool.Pool) error {
+	return prometheus.Register(newPoolCollector(pool))
+}
+
+// newPoolCollector returns a collector reading the statistics of pool.
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		pool:         pool,
+		acquired:     desc("acquired_connections", "Connections currently checked out of the pool."),
+		idle:         desc("idle_connections", "Idle connections in the pool."),
+		constructing: desc("constructing_connections", "Connections being opened."),
+		total:        desc("total_connections", "Connections currently open."),
+		max:          desc("max_connections", "Maximum size of the pool."),
+		acquires:     desc("acquires_total", "Connections acquired from the pool."),
+		acquireTime:  desc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+		waits:        desc("acquire_waits_total", "Acquires that had to wait for a connection because the pool was empty."),
+		waitTime:     desc("acquire_wait_duration_seconds_total", "Total time spent waiting for a connection when the pool was empty."),
+		canceled:     desc("canceled_acquires_total", "Acquires cancelled by their context before a connection was available."),
+	}
+}
+
+func (p *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		p.acquired, p.idle, p.constructing, p.total, p.max,
+		p.acquires, p.acquireTime, p.waits, p.waitTime, p.canceled,
+	} {
+		ch <- d
+	}
+}
+
+func (p *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := p.pool.Stat()
+	gauge := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v)
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v)
+	}
+
+	gauge(p.acquired, float64(s.AcquiredConns()))
+	gauge(p.idle, float64(s.IdleConns()))
+	gauge(p.constructing, float64(s.ConstructingConns()))
+	gauge(p.total, float64(s.TotalConns()))
+	gauge(p.max, float64(s.MaxConns()))
+	counter(p.acquires, float64(s.AcquireCount()))
+	counter(p.acquireTime, s.AcquireDuration().Seconds())
+	counter(p.waits, float64(s.EmptyAcquireCount()))
+	counter(p.waitTime, s.EmptyAcquireWaitTime().Seconds())
+	counter(p.canceled, float64(s.CanceledAcquireCount()))
+}
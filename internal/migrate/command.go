@@ -0,0 +1,72 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RunCommand implements the "migrate" subcommand shared by the server and
+// zysjctl, writing progress to out:
+//
+//	migrate up           apply all pending migrations
+//	migrate down [n]     roll back the last n migrations (default 1)
+//	migrate status       list migrations and when they were applied
+//	migrate force <v>    mark the database as being at version v
+func RunCommand(ctx context.Context, pool *pgxpool.Pool, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [n]|status|force <version>")
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := Up(ctx, pool)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Applied %d migration(s)\n", n)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+		}
+		n, err := Down(ctx, pool, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Rolled back %d migration(s)\n", n)
+	case "status":
+		statuses, err := List(ctx, pool)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%06d  %-40s %s\n", s.Version, s.Name, applied)
+		}
+	case "force":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: migrate force <version>")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version: %s", args[1])
+		}
+		if err := Force(ctx, pool, version); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Database marked as version %d\n", version)
+	default:
+		return fmt.Errorf("unknown migrate command: %s", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package migrate
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d has version %d, want versions numbered from 1 without gaps", i, m.Version)
+		}
+		if m.Up == "" || m.Down == "" {
+			t.Errorf("migration %06d_%s is missing its up or down script", m.Version, m.Name)
+		}
+	}
+
+	latest, err := LatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := migrations[len(migrations)-1].Version; latest != want {
+		t.Errorf("LatestVersion() = %d, want %d", latest, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	script := func(sql string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(sql)} }
+
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		versions []int64
+		wantErr  string
+	}{
+		{
+			name: "ordered by version",
+			files: fstest.MapFS{
+				"000010_ten.up.sql":   script("ten up"),
+				"000010_ten.down.sql": script("ten down"),
+				"000002_two.up.sql":   script("two up"),
+				"000002_two.down.sql": script("two down"),
+				"000001_one.up.sql":   script("one up"),
+				"README.md":           script("not a migration"),
+			},
+			versions: []int64{1, 2, 10},
+		},
+		{
+			name: "missing up script",
+			files: fstest.MapFS{
+				"000001_one.up.sql":   script("one up"),
+				"000002_two.down.sql": script("two down"),
+			},
+			wantErr: "migration 2 (two) has no up script",
+		},
+		{
+			name: "duplicate version",
+			files: fstest.MapFS{
+				"000001_one.up.sql":   script("one up"),
+				"000001_uno.up.sql":   script("uno up"),
+				"000001_one.down.sql": script("one down"),
+			},
+			wantErr: "conflicting names",
+		},
+		{
+			name: "duplicate script",
+			files: fstest.MapFS{
+				"000001_one.up.sql": script("one up"),
+				"1_one.up.sql":      script("one up again"),
+			},
+			wantErr: "more than one up script",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := load(tt.files)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("load() error = %v", err)
+			}
+			var versions []int64
+			for _, m := range migrations {
+				versions = append(versions, m.Version)
+			}
+			if len(versions) != len(tt.versions) {
+				t.Fatalf("versions = %v, want %v", versions, tt.versions)
+			}
+			for i := range versions {
+				if versions[i] != tt.versions[i] {
+					t.Fatalf("versions = %v, want %v", versions, tt.versions)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadScripts(t *testing.T) {
+	migrations, err := load(fstest.MapFS{
+		"000001_one.up.sql":   {Data: []byte("CREATE TABLE one ()")},
+		"000001_one.down.sql": {Data: []byte("DROP TABLE one")},
+		"000002_two.up.sql":   {Data: []byte("CREATE TABLE two ()")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Migration{
+		{Version: 1, Name: "one", Up: "CREATE TABLE one ()", Down: "DROP TABLE one"},
+		{Version: 2, Name: "two", Up: "CREATE TABLE two ()"},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("got %d migrations, want %d", len(migrations), len(want))
+	}
+	for i := range want {
+		if migrations[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, migrations[i], want[i])
+		}
+	}
+}
+
+// TestRunCommandArguments checks the usage errors, which are reported
+// before the database is touched.
+func TestRunCommandArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"no command", nil, "usage: migrate"},
+		{"unknown command", []string{"sideways"}, "unknown migrate command: sideways"},
+		{"down not a number", []string{"down", "two"}, "invalid number of steps: two"},
+		{"down zero", []string{"down", "0"}, "invalid number of steps: 0"},
+		{"force without version", []string{"force"}, "usage: migrate force <version>"},
+		{"force not a number", []string{"force", "latest"}, "invalid version: latest"},
+		{"force negative", []string{"force", "-1"}, "invalid version: -1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RunCommand(context.Background(), nil, tt.args, io.Discard)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("RunCommand(%q) error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
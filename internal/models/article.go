@@ -4,12 +4,20 @@ import "time"
 
 // Article represents the structure of our articles table
 type Article struct {
-	ID         int64     `json:"id"`
-	Title      string    `json:"title"`
-	Content    string    `json:"content"`
-	CategoryID NullInt64 `json:"category_id,omitempty"` // A category might be optional
-	Author     string    `json:"author,omitempty"`
-	Source     string    `json:"source,omitempty"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
-}
\ No newline at end of file
+	ID            int64      `json:"id"`
+	Title         string     `json:"title"`
+	Slug          string     `json:"slug"`
+	Content       string     `json:"content"`
+	ContentFormat string     `json:"content_format"` // plain, markdown or html
+	ContentHTML   string     `json:"-"`              // Sanitised HTML rendered from Content
+	TOC           []TOCEntry `json:"toc,omitempty"`  // Headings extracted from ContentHTML
+	Excerpt       string     `json:"excerpt"`
+	WordCount     int        `json:"word_count"`
+	CharCount     int        `json:"char_count"`
+	CategoryID    NullInt64  `json:"category_id,omitempty"` // A category might be optional
+	Author        string     `json:"author,omitempty"`
+	Source        string     `json:"source,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Version       int64      `json:"version"` // Incremented by every update
+}
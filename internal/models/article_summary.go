@@ -0,0 +1,115 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// NullString is a string that can be NULL in the database.
+type NullString struct {
+	String string
+	Valid  bool // Valid is true if String is not NULL
+}
+
+// ArticleSummaryFields lists every field of ArticleSummary in the order
+// they are serialised when no selection is made.
+var ArticleSummaryFields = []string{
+	"id", "title", "slug", "content", "content_format", "excerpt", "word_count", "char_count",
+	"category_id", "category_name", "author", "source", "created_at", "updated_at",
+}
+
+// ArticleSummary is a row of the article list endpoints. Clients choose the
+// fields they want, so only those named in Fields are serialised, in that
+// order; nullable columns come out as null when selected but NULL.
+type ArticleSummary struct {
+	ID            int64      `json:"id"`
+	Title         string     `json:"title,omitzero"`
+	Slug          string     `json:"slug,omitzero"`
+	Content       string     `json:"content,omitzero"`
+	ContentFormat string     `json:"content_format,omitzero"`
+	Excerpt       string     `json:"excerpt,omitzero"`
+	WordCount     int        `json:"word_count,omitzero"`
+	CharCount     int        `json:"char_count,omitzero"`
+	CategoryID    NullInt64  `json:"category_id,omitzero"`
+	CategoryName  NullString `json:"category_name,omitzero"`
+	Author        NullString `json:"author,omitzero"`
+	Source        NullString `json:"source,omitzero"`
+	CreatedAt     time.Time  `json:"created_at,omitzero"`
+	UpdatedAt     time.Time  `json:"updated_at,omitzero"`
+
+	Fields []string `json:"-"` // Selected fields, id first; all when empty
+}
+
+// Field returns the value of the named field, and false for unknown names.
+func (s ArticleSummary) Field(name string) (interface{}, bool) {
+	switch name {
+	case "id":
+		return s.ID, true
+	case "title":
+		return s.Title, true
+	case "slug":
+		return s.Slug, true
+	case "content":
+		return s.Content, true
+	case "content_format":
+		return s.ContentFormat, true
+	case "excerpt":
+		return s.Excerpt, true
+	case "word_count":
+		return s.WordCount, true
+	case "char_count":
+		return s.CharCount, true
+	case "category_id":
+		return nullable(s.CategoryID.Int64, s.CategoryID.Valid), true
+	case "category_name":
+		return nullable(s.CategoryName.String, s.CategoryName.Valid), true
+	case "author":
+		return nullable(s.Author.String, s.Author.Valid), true
+	case "source":
+		return nullable(s.Source.String, s.Source.Valid), true
+	case "created_at":
+		return s.CreatedAt, true
+	case "updated_at":
+		return s.UpdatedAt, true
+	}
+	return nil, false
+}
+
+// MarshalJSON writes the selected fields only, or all of them without a
+// selection.
+func (s ArticleSummary) MarshalJSON() ([]byte, error) {
+	fields := s.Fields
+	if len(fields) == 0 {
+		fields = ArticleSummaryFields
+	}
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for _, name := range fields {
+		v, ok := s.Field(name)
+		if !ok {
+			continue
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func nullable(v interface{}, valid bool) interface{} {
+	if !valid {
+		return nil
+	}
+	return v
+}
@@ -19,4 +19,5 @@ type Category struct {
 	Description string    `json:"description,omitempty"`
 	ParentID    NullInt64 `json:"parent_id,omitempty"` // Use NullInt64 for nullable foreign keys
 	CreatedAt   time.Time `json:"created_at"`
+	Version     int64     `json:"version"` // Incremented by every update
 }
\ No newline at end of file
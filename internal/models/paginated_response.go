@@ -7,9 +7,26 @@ type PaginatedResponse struct {
 }
 
 // Pagination holds the metadata for a paginated list.
+// TotalItems and TotalPages are omitted when the client skipped the count.
 type Pagination struct {
-	CurrentPage int   `json:"currentPage"`
-	PageSize    int   `json:"pageSize"`
-	TotalItems  int64 `json:"totalItems"`
-	TotalPages  int   `json:"totalPages"`
-}
\ No newline at end of file
+	CurrentPage     int    `json:"currentPage"`
+	PageSize        int    `json:"pageSize"`
+	TotalItems      *int64 `json:"totalItems,omitempty"`
+	TotalPages      *int   `json:"totalPages,omitempty"`
+	TotalsEstimated bool   `json:"totalsEstimated,omitempty"` // Totals come from planner statistics
+	HasMore         bool   `json:"hasMore"`
+}
+
+// CursorPaginatedResponse defines the structure for keyset-paginated API responses.
+type CursorPaginatedResponse struct {
+	Data       interface{}      `json:"data"`
+	Pagination CursorPagination `json:"pagination"`
+}
+
+// CursorPagination holds the metadata for a keyset-paginated list.
+// NextCursor is empty on the last page.
+type CursorPagination struct {
+	PageSize   int    `json:"pageSize"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+}
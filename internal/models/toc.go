@@ -0,0 +1,8 @@
+package models
+
+// TOCEntry is a single heading in an article's table of contents.
+type TOCEntry struct {
+	Level  int    `json:"level"`
+	Text   string `json:"text"`
+	Anchor string `json:"anchor"`
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// refilled is the token count of bucket b once refilled up to now, with
+// the burst as $2 and the rate as $3.
+const refilled = `LEAST($2::float8, b.tokens + extract(epoch FROM now() - b.updated_at)::float8 * $3::float8)`
+
+// takeToken takes a token from the bucket of $1, creating it full if it
+// doesn't exist. It returns no row when the bucket is empty.
+const takeToken = `
+INSERT INTO rate_limits AS b (key, tokens, updated_at, full_at)
+VALUES ($1, $2::float8 - 1, now(), now() + make_interval(secs => 1 / $3::float8))
+ON CONFLICT (key) DO UPDATE SET
+    tokens = ` + refilled + ` - 1,
+    updated_at = now(),
+    full_at = now() + make_interval(secs => ($2::float8 - ` + refilled + ` + 1) / $3::float8)
+WHERE ` + refilled + ` >= 1
+RETURNING tokens`
+
+// Postgres is a Limiter keeping buckets in the rate_limits table, so that
+// limits hold across every instance of the API. Each request costs one
+// statement, two when it is refused.
+type Postgres struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgres returns a Limiter storing buckets through pool.
+func NewPostgres(pool *pgxpool.Pool) *Postgres {
+	return &Postgres{pool: pool}
+}
+
+var _ Limiter = (*Postgres)(nil)
+
+func (p *Postgres) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	var tokens float64
+	rows, err := p.pool.Query(ctx, takeToken, key, float64(limit.Burst), limit.Rate)
+	if err != nil {
+		return Result{}, err
+	}
+	allowed := rows.Next()
+	if allowed {
+		err = rows.Scan(&tokens)
+	}
+	rows.Close()
+	if err == nil {
+		err = rows.Err()
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	if !allowed {
+		err := p.pool.QueryRow(ctx, `SELECT `+refilled+` FROM rate_limits b WHERE key = $1`, key, float64(limit.Burst), limit.Rate).Scan(&tokens)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+	return newResult(limit, tokens, allowed), nil
+}
+
+// Sweep deletes the buckets that have refilled every interval, until ctx
+// is done.
+func (p *Postgres) Sweep(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		tag, err := p.pool.Exec(ctx, `DELETE FROM rate_limits WHERE full_at <= now()`)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.WarnContext(ctx, "Failed to sweep rate limit buckets", "error", err)
+			}
+			continue
+		}
+		slog.DebugContext(ctx, "Swept rate limit buckets", "count", tag.RowsAffected())
+	}
+}
@@ -0,0 +1,57 @@
+// Package ratelimit throttles clients with token buckets.
+//
+// Every key (a route group and a client) has a bucket holding up to Burst
+// tokens, refilled at Rate tokens per second. A request takes a token, and
+// is refused when the bucket is empty. Buckets live in process by default;
+// the Postgres limiter shares them between every instance of the API.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is the size and refill rate of a bucket. A zero Rate disables the
+// limit.
+type Limit struct {
+	Rate  float64 // Tokens added per second
+	Burst int     // Capacity of the bucket
+}
+
+// Enabled reports whether the limit throttles anything.
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Result is the outcome of a request for a token.
+type Result struct {
+	Allowed    bool
+	Remaining  int           // Whole tokens left in the bucket
+	Reset      time.Duration // Until the bucket is full again
+	RetryAfter time.Duration // Until the next token, when not allowed
+}
+
+// Limiter hands out tokens. Implementations must be safe for concurrent
+// use.
+type Limiter interface {
+	// Allow takes a token from the bucket of key, sized by limit.
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// newResult describes a bucket left with tokens after a request.
+func newResult(limit Limit, tokens float64, allowed bool) Result {
+	r := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     seconds((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+	if !allowed {
+		r.RetryAfter = seconds((1 - tokens) / limit.Rate)
+	}
+	return r
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Max(0, s) * float64(time.Second))
+}
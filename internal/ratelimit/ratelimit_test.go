@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimitEnabled(t *testing.T) {
+	tests := []struct {
+		limit Limit
+		want  bool
+	}{
+		{Limit{Rate: 1, Burst: 1}, true},
+		{Limit{Rate: 0, Burst: 10}, false},
+		{Limit{Rate: 5, Burst: 0}, false},
+		{Limit{}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.limit.Enabled(); got != tt.want {
+			t.Errorf("%+v.Enabled() = %v, want %v", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	limit := Limit{Rate: 2, Burst: 10}
+
+	tests := []struct {
+		name    string
+		tokens  float64
+		allowed bool
+		want    Result
+	}{
+		{"full", 10, true, Result{Allowed: true, Remaining: 10}},
+		{"one taken", 9, true, Result{Allowed: true, Remaining: 9, Reset: 500 * time.Millisecond}},
+		{"partial token", 3.5, true, Result{Allowed: true, Remaining: 3, Reset: 3250 * time.Millisecond}},
+		{"emptied", 0, true, Result{Allowed: true, Remaining: 0, Reset: 5 * time.Second}},
+		{"refused", 0.5, false, Result{Remaining: 0, Reset: 4750 * time.Millisecond, RetryAfter: 250 * time.Millisecond}},
+		{"refused when empty", 0, false, Result{Remaining: 0, Reset: 5 * time.Second, RetryAfter: 500 * time.Millisecond}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newResult(limit, tt.tokens, tt.allowed); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	// Refills too slowly to matter within the test
+	limit := Limit{Rate: 0.001, Burst: 3}
+
+	tests := []struct {
+		key           string
+		wantAllowed   bool
+		wantRemaining int
+	}{
+		{"a", true, 2},
+		{"a", true, 1},
+		{"b", true, 2}, // Separate bucket
+		{"a", true, 0},
+		{"a", false, 0},
+		{"a", false, 0},
+		{"b", true, 1},
+	}
+	m := NewMemory()
+	for i, tt := range tests {
+		res, err := m.Allow(ctx, tt.key, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Allowed != tt.wantAllowed || res.Remaining != tt.wantRemaining {
+			t.Errorf("request %d for %q: got allowed %v, remaining %d, want %v, %d",
+				i, tt.key, res.Allowed, res.Remaining, tt.wantAllowed, tt.wantRemaining)
+		}
+		if !res.Allowed && res.RetryAfter <= 0 {
+			t.Errorf("request %d for %q: refused without Retry-After", i, tt.key)
+		}
+	}
+}
+
+func TestMemoryRefills(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{Rate: 100, Burst: 1}
+	m := NewMemory()
+
+	if res, _ := m.Allow(ctx, "k", limit); !res.Allowed {
+		t.Fatal("first request refused")
+	}
+	if res, _ := m.Allow(ctx, "k", limit); res.Allowed {
+		t.Fatal("second request allowed with an empty bucket")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if res, _ := m.Allow(ctx, "k", limit); !res.Allowed {
+		t.Error("request refused after the bucket refilled")
+	}
+}
+
+func TestMemorySweep(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	m.Allow(ctx, "full", Limit{Rate: 1000, Burst: 1})
+	m.Allow(ctx, "draining", Limit{Rate: 0.001, Burst: 5})
+
+	m.sweep(time.Now().Add(time.Second))
+	if _, ok := m.buckets["full"]; ok {
+		t.Error("refilled bucket kept")
+	}
+	if _, ok := m.buckets["draining"]; !ok {
+		t.Error("bucket still refilling was dropped")
+	}
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jalikey/zysj-backend/internal/models"
+)
+
+// lastUsedResolution is how stale last_used_at may get, so that a busy key
+// doesn't cost a write on every request.
+const lastUsedResolution = "1 minute"
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, created_by, created_at, expires_at, revoked_at, last_used_at`
+
+// CreateAPIKey inserts a new API key.
+func (s *PostgresStore) CreateAPIKey(ctx context.Context, key models.APIKey) (int64, error) {
+	ctx, done := instrument(ctx, "CreateAPIKey")
+	defer done()
+	var id int64
+	query := `INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	err := s.db.QueryRow(ctx, query, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.CreatedBy, key.ExpiresAt).Scan(&id)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error creating API key", "error", err)
+		return 0, translate(err, "API key")
+	}
+	return id, nil
+}
+
+// GetAPIKeyByHash finds an API key, including revoked and expired ones,
+// by the hash of its secret.
+func (s *PostgresStore) GetAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error) {
+	ctx, done := instrument(ctx, "GetAPIKeyByHash")
+	defer done()
+	var key models.APIKey
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+	err := s.db.QueryRow(ctx, query, hash).Scan(
+		&key.ID, &key.Name, &key.Prefix, &key.KeyHash, &key.Scopes, &key.CreatedBy,
+		&key.CreatedAt, &key.ExpiresAt, &key.RevokedAt, &key.LastUsedAt,
+	)
+	if err != nil {
+		return models.APIKey{}, translate(err, "API key")
+	}
+	return key, nil
+}
+
+// GetAllAPIKeys returns every API key, newest first.
+func (s *PostgresStore) GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	ctx, done := instrument(ctx, "GetAllAPIKeys")
+	defer done()
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at DESC, id DESC`
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error querying API keys", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		var key models.APIKey
+		if err := rows.Scan(
+			&key.ID, &key.Name, &key.Prefix, &key.KeyHash, &key.Scopes, &key.CreatedBy,
+			&key.CreatedAt, &key.ExpiresAt, &key.RevokedAt, &key.LastUsedAt,
+		); err != nil {
+			slog.ErrorContext(ctx, "Error scanning API key", "error", err)
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey stops an API key from being accepted. Revoking a key twice
+// keeps the time of the first revocation. It returns ErrNotFound when no
+// key has that ID.
+func (s *PostgresStore) RevokeAPIKey(ctx context.Context, id int64) error {
+	ctx, done := instrument(ctx, "RevokeAPIKey")
+	defer done()
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1`
+	tag, err := s.db.Exec(ctx, query, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error revoking API key", "error", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("API key")
+	}
+	return nil
+}
+
+// TouchAPIKey records that an API key was just used. The time is only
+// updated once it is older than lastUsedResolution.
+func (s *PostgresStore) TouchAPIKey(ctx context.Context, id int64) error {
+	ctx, done := instrument(ctx, "TouchAPIKey")
+	defer done()
+	query := `UPDATE api_keys SET last_used_at = now()
+	          WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '` + lastUsedResolution + `')`
+	_, err := s.db.Exec(ctx, query, id)
+	return err
+}
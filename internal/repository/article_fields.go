@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jalikey/zysj-backend/internal/models"
+)
+
+// DefaultArticleListFields is the summary projection returned by list
+// endpoints when the client does not ask for specific fields.
+var DefaultArticleListFields = []string{
+	"id", "title", "slug", "excerpt", "word_count", "char_count",
+	"category_id", "category_name", "author", "created_at", "updated_at",
+}
+
+// articleListColumns maps every field a client may request from the list
+// endpoints to the SQL expression that selects it. List queries join
+// categories as c onto articles as a.
+var articleListColumns = map[string]string{
+	"id":             "a.id",
+	"title":          "a.title",
+	"slug":           "a.slug",
+	"content":        "a.content",
+	"content_format": "a.content_format",
+	"excerpt":        "a.excerpt",
+	"word_count":     "a.word_count",
+	"char_count":     "a.char_count",
+	"category_id":    "a.category_id",
+	"category_name":  "c.name",
+	"author":         "a.author",
+	"source":         "a.source",
+	"created_at":     "a.created_at",
+	"updated_at":     "a.updated_at",
+}
+
+// IsArticleListField reports whether name can be requested from the article
+// list endpoints.
+func IsArticleListField(name string) bool {
+	_, ok := articleListColumns[name]
+	return ok
+}
+
+// articleFields resolves the requested fields into the projection that is
+// selected: the default summary when none are requested, id first, without
+// duplicates. Only whitelisted fields are accepted.
+func articleFields(fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		fields = DefaultArticleListFields
+	}
+	projection := []string{"id"}
+	seen := map[string]bool{"id": true}
+	for _, f := range fields {
+		if seen[f] {
+			continue
+		}
+		if _, ok := articleListColumns[f]; !ok {
+			return nil, fmt.Errorf("unknown article field: %q", f)
+		}
+		seen[f] = true
+		projection = append(projection, f)
+	}
+	return projection, nil
+}
+
+// articleSelectList builds the SELECT list for the requested fields.
+func articleSelectList(fields []string) (string, error) {
+	projection, err := articleFields(fields)
+	if err != nil {
+		return "", err
+	}
+	cols := make([]string, len(projection))
+	for i, f := range projection {
+		cols[i] = articleListColumns[f] + " AS " + f
+	}
+	return strings.Join(cols, ", "), nil
+}
+
+// articleRow is a scanned row of a list query: the summary, plus the
+// position of the row for keyset queries that select it.
+type articleRow struct {
+	summary   models.ArticleSummary
+	createdAt time.Time // cursor_created_at
+	rank      float32   // cursor_rank
+
+	// Nullable columns, copied into the summary after scanning
+	categoryID                   sql.NullInt64
+	categoryName, author, source sql.NullString
+}
+
+// queryArticleRows runs a list query and scans each row into a summary. The
+// selected columns, named after the list fields, become the summary's
+// Fields, apart from the cursor columns of keyset queries.
+func queryArticleRows(ctx context.Context, q querier, query string, args ...interface{}) ([]articleRow, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fields []string
+	for _, fd := range rows.FieldDescriptions() {
+		if _, ok := articleListColumns[fd.Name]; ok {
+			fields = append(fields, fd.Name)
+		}
+	}
+
+	var result []articleRow
+	for rows.Next() {
+		row := articleRow{summary: models.ArticleSummary{Fields: fields}}
+		dests := make([]interface{}, 0, len(rows.FieldDescriptions()))
+		for _, fd := range rows.FieldDescriptions() {
+			dests = append(dests, row.dest(fd.Name))
+		}
+		if err := rows.Scan(dests...); err != nil {
+			return nil, err
+		}
+		row.summary.CategoryID = models.NullInt64{Int64: row.categoryID.Int64, Valid: row.categoryID.Valid}
+		row.summary.CategoryName = models.NullString{String: row.categoryName.String, Valid: row.categoryName.Valid}
+		row.summary.Author = models.NullString{String: row.author.String, Valid: row.author.Valid}
+		row.summary.Source = models.NullString{String: row.source.String, Valid: row.source.Valid}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// dest returns where to scan the column of the given name.
+func (r *articleRow) dest(column string) interface{} {
+	s := &r.summary
+	switch column {
+	case "id":
+		return &s.ID
+	case "title":
+		return &s.Title
+	case "slug":
+		return &s.Slug
+	case "content":
+		return &s.Content
+	case "content_format":
+		return &s.ContentFormat
+	case "excerpt":
+		return &s.Excerpt
+	case "word_count":
+		return &s.WordCount
+	case "char_count":
+		return &s.CharCount
+	case "category_id":
+		return &r.categoryID
+	case "category_name":
+		return &r.categoryName
+	case "author":
+		return &r.author
+	case "source":
+		return &r.source
+	case "created_at":
+		return &s.CreatedAt
+	case "updated_at":
+		return &s.UpdatedAt
+	case "cursor_created_at":
+		return &r.createdAt
+	case "cursor_rank":
+		return &r.rank
+	}
+	return nil // Unreachable: every list query selects known columns only
+}
+
+// summaries returns the summaries of rows.
+func summaries(rows []articleRow) []models.ArticleSummary {
+	out := make([]models.ArticleSummary, len(rows))
+	for i, r := range rows {
+		out[i] = r.summary
+	}
+	return out
+}
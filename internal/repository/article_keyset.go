@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jalikey/zysj-backend/internal/models"
+)
+
+// Keyset (cursor) pagination for the article list endpoints. Unlike
+// LIMIT/OFFSET, each page continues strictly after the last row of the
+// previous one, so deep pages stay cheap and concurrent inserts don't cause
+// duplicates or skipped rows. One extra row is fetched to find out whether
+// another page follows.
+
+// GetArticlesAfter returns up to limit articles following the cursor, newest
+// first, together with the cursor of the next page ("" on the last page).
+func (s *PostgresStore) GetArticlesAfter(ctx context.Context, fields []string, after string, limit int) ([]models.ArticleSummary, string, error) {
+	ctx, done := instrument(ctx, "GetArticlesAfter")
+	defer done()
+	articles, next, err := listArticlesAfter(ctx, s.db, allArticlesListing, "", nil, fields, after, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error querying articles by cursor", "error", err)
+	}
+	return articles, next, err
+}
+
+// GetArticlesByCategoryIDAfter is the keyset variant of GetArticlesByCategoryID.
+func (s *PostgresStore) GetArticlesByCategoryIDAfter(ctx context.Context, categoryID int64, fields []string, after string, limit int) ([]models.ArticleSummary, string, error) {
+	ctx, done := instrument(ctx, "GetArticlesByCategoryIDAfter")
+	defer done()
+	articles, next, err := listArticlesAfter(ctx, s.db, categoryListing(categoryID), "a.category_id = $1", []interface{}{categoryID}, fields, after, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error querying articles by category ID and cursor", "error", err)
+	}
+	return articles, next, err
+}
+
+// SearchArticlesAfter is the keyset variant of SearchArticles, ordered by
+// rank and then id.
+func (s *PostgresStore) SearchArticlesAfter(ctx context.Context, query string, fields []string, after string, limit int) ([]models.ArticleSummary, string, error) {
+	ctx, done := instrument(ctx, "SearchArticlesAfter")
+	defer done()
+	listing := searchListing(query)
+	cursor, err := DecodeCursor(after, listing)
+	if err != nil {
+		return nil, "", err
+	}
+	selectList, err := articleSelectList(fields)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rank := `ts_rank(a.content_tsv, plainto_tsquery('simple', $1))`
+	args := []interface{}{query}
+	conds := []string{`a.content_tsv @@ plainto_tsquery('simple', $1)`}
+	if cursor != nil {
+		args = append(args, cursor.Rank, cursor.ID)
+		conds = append(conds, fmt.Sprintf("(%s, a.id) < ($%d::real, $%d)", rank, len(args)-1, len(args)))
+	}
+	args = append(args, limit+1)
+
+	sqlQuery := `SELECT ` + selectList + `, ` + rank + ` AS cursor_rank
+				 FROM articles a LEFT JOIN categories c ON c.id = a.category_id
+				 WHERE ` + strings.Join(conds, " AND ") + `
+				 ORDER BY cursor_rank DESC, a.id DESC
+				 LIMIT $` + fmt.Sprint(len(args))
+
+	articles, err := queryArticleRows(ctx, s.db, sqlQuery, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error searching articles by cursor", "error", err)
+		return nil, "", err
+	}
+
+	next := ""
+	if len(articles) > limit {
+		articles = articles[:limit]
+		last := articles[limit-1]
+		next = EncodeCursor(Cursor{Listing: listing, Rank: last.rank, ID: last.summary.ID})
+	}
+	return summaries(articles), next, nil
+}
+
+// listArticlesAfter runs a keyset query over (created_at, id) with an
+// optional extra condition whose arguments come first. Cursors are bound
+// to listing.
+func listArticlesAfter(ctx context.Context, q querier, listing, where string, args []interface{}, fields []string, after string, limit int) ([]models.ArticleSummary, string, error) {
+	cursor, err := DecodeCursor(after, listing)
+	if err != nil {
+		return nil, "", err
+	}
+	selectList, err := articleSelectList(fields)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var conds []string
+	if where != "" {
+		conds = append(conds, where)
+	}
+	if cursor != nil {
+		args = append(args, cursor.CreatedAt, cursor.ID)
+		conds = append(conds, fmt.Sprintf("(a.created_at, a.id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	args = append(args, limit+1)
+
+	query := `SELECT ` + selectList + `, a.created_at AS cursor_created_at
+			  FROM articles a LEFT JOIN categories c ON c.id = a.category_id`
+	if len(conds) > 0 {
+		query += `
+			  WHERE ` + strings.Join(conds, " AND ")
+	}
+	query += `
+			  ORDER BY a.created_at DESC, a.id DESC
+			  LIMIT $` + fmt.Sprint(len(args))
+
+	articles, err := queryArticleRows(ctx, q, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(articles) > limit {
+		articles = articles[:limit]
+		last := articles[limit-1]
+		next = EncodeCursor(Cursor{Listing: listing, CreatedAt: last.createdAt, ID: last.summary.ID})
+	}
+	return summaries(articles), next, nil
+}
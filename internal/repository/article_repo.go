@@ -3,84 +3,92 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"log"
+	"errors"
+	"log/slog"
 
-	"github.com/jalikey/zysj-backend/internal/database" // !! 修改为你的模块路径
+	"github.com/jackc/pgx/v5"
 	"github.com/jalikey/zysj-backend/internal/models"   // !! 修改为你的模块路径
 )
 
 // GetAllArticles queries the database and returns all articles.
 // GetAllArticles now supports pagination.
-// It returns a slice of articles for the current page and the total count of all articles.
-func GetAllArticles(limit, offset int) ([]models.Article, int64, error) {
-	// Query for the current page of articles
-	query := `SELECT id, title, content, category_id, author, source, created_at, updated_at 
-			  FROM articles 
-			  ORDER BY created_at DESC
-			  LIMIT $1 OFFSET $2`
-	
-	rows, err := database.DB.Query(context.Background(), query, limit, offset)
+// It returns the requested fields of the articles on the current page and the total count of all articles,
+// computed according to the count mode.
+func (s *PostgresStore) GetAllArticles(ctx context.Context, fields []string, limit, offset int, count CountMode) ([]models.ArticleSummary, PageInfo, error) {
+	ctx, done := instrument(ctx, "GetAllArticles")
+	defer done()
+	selectList, err := articleSelectList(fields)
 	if err != nil {
-		log.Printf("Error querying paginated articles: %v\n", err)
-		return nil, 0, err
-	}
-	defer rows.Close()
-
-	var articles []models.Article
-	for rows.Next() {
-		// ... (scan logic remains the same as before) ...
-		var article models.Article
-		var categoryID sql.NullInt64
-		if err := rows.Scan(&article.ID, &article.Title, &article.Content, &categoryID, &article.Author, &article.Source, &article.CreatedAt, &article.UpdatedAt); err != nil {
-			log.Printf("Error scanning article row: %v\n", err)
-			return nil, 0, err
-		}
-		if categoryID.Valid {
-			article.CategoryID = models.NullInt64{Int64: categoryID.Int64, Valid: true}
-		}
-		articles = append(articles, article)
+		return nil, PageInfo{}, err
 	}
 
-	// Query for the total count of articles
-	var totalItems int64
-	countQuery := `SELECT COUNT(*) FROM articles`
-	err = database.DB.QueryRow(context.Background(), countQuery).Scan(&totalItems)
+	// Query for the current page of articles
+	query := `SELECT ` + selectList + `
+			  FROM articles a LEFT JOIN categories c ON c.id = a.category_id
+			  ORDER BY a.created_at DESC, a.id DESC
+			  LIMIT $1 OFFSET $2`
+
+	articles, info, err := listPage(ctx, s.db, query, []interface{}{limit + 1, offset}, `FROM articles`, nil, limit, offset, count)
 	if err != nil {
-		log.Printf("Error counting articles: %v\n", err)
-		return nil, 0, err
+		slog.ErrorContext(ctx, "Error querying paginated articles", "error", err)
+		return nil, PageInfo{}, err
 	}
-
-	return articles, totalItems, nil
+	return articles, info, nil
 }
 
 
 // ... GetAllArticles() 函数保持不变 ...
 
 // GetArticleByID queries the database for a single article by its ID.
-func GetArticleByID(id int64) (models.Article, error) {
+func (s *PostgresStore) GetArticleByID(ctx context.Context, id int64) (models.Article, error) {
+	ctx, done := instrument(ctx, "GetArticleByID")
+	defer done()
+	return s.getArticle(ctx, `id = $1`, id)
+}
+
+// GetArticleBySlug queries the database for a single article by its slug.
+// Slugs the article used before being renamed are not matched; see
+// ArticleSlugRedirect.
+func (s *PostgresStore) GetArticleBySlug(ctx context.Context, slug string) (models.Article, error) {
+	ctx, done := instrument(ctx, "GetArticleBySlug")
+	defer done()
+	return s.getArticle(ctx, `slug = $1`, slug)
+}
+
+// getArticle returns the single article matching the WHERE condition.
+func (s *PostgresStore) getArticle(ctx context.Context, condition string, arg interface{}) (models.Article, error) {
 	query := `
-		SELECT id, title, content, category_id, author, source, created_at, updated_at 
+		SELECT id, title, slug, content, content_format, content_html, toc, excerpt, word_count, char_count, category_id, author, source, created_at, updated_at, version 
 		FROM articles 
-		WHERE id = $1
-	`
+		WHERE ` + condition
 	var article models.Article
 	var categoryID sql.NullInt64
 
-	row := database.DB.QueryRow(context.Background(), query, id)
+	row := s.db.QueryRow(ctx, query, arg)
 	err := row.Scan(
 		&article.ID,
 		&article.Title,
+		&article.Slug,
 		&article.Content,
+		&article.ContentFormat,
+		&article.ContentHTML,
+		&article.TOC,
+		&article.Excerpt,
+		&article.WordCount,
+		&article.CharCount,
 		&categoryID,
 		&article.Author,
 		&article.Source,
 		&article.CreatedAt,
 		&article.UpdatedAt,
+		&article.Version,
 	)
 
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		slog.ErrorContext(ctx, "Error scanning single article row", "error", err)
+	}
 	if err != nil {
-		log.Printf("Error scanning single article row: %v\n", err)
-		return models.Article{}, err
+		return models.Article{}, translate(err, "article")
 	}
 	
 	if categoryID.Valid {
@@ -91,85 +99,66 @@ func GetArticleByID(id int64) (models.Article, error) {
 }
 
 // GetArticlesByCategoryID now supports pagination.
-func GetArticlesByCategoryID(categoryID int64, limit, offset int) ([]models.Article, int64, error) {
-	query := `SELECT id, title, content, category_id, author, source, created_at, updated_at 
-			  FROM articles 
-			  WHERE category_id = $1
-			  ORDER BY created_at DESC
-			  LIMIT $2 OFFSET $3`
-			  
-	rows, err := database.DB.Query(context.Background(), query, categoryID, limit, offset)
-	// ... (scan logic is identical to GetAllArticles) ...
-	if err != nil { log.Printf("Error querying articles by category ID: %v\n", err); return nil, 0, err }
-	defer rows.Close()
-	var articles []models.Article
-	for rows.Next() {
-		var article models.Article
-		var catID sql.NullInt64
-		if err := rows.Scan(&article.ID, &article.Title, &article.Content, &catID, &article.Author, &article.Source, &article.CreatedAt, &article.UpdatedAt); err != nil {
-			log.Printf("Error scanning article row: %v\n", err); return nil, 0, err
-		}
-        if catID.Valid { article.CategoryID = models.NullInt64{Int64: catID.Int64, Valid: true} }
-		articles = append(articles, article)
+func (s *PostgresStore) GetArticlesByCategoryID(ctx context.Context, categoryID int64, fields []string, limit, offset int, count CountMode) ([]models.ArticleSummary, PageInfo, error) {
+	ctx, done := instrument(ctx, "GetArticlesByCategoryID")
+	defer done()
+	selectList, err := articleSelectList(fields)
+	if err != nil {
+		return nil, PageInfo{}, err
 	}
 
+	query := `SELECT ` + selectList + `
+			  FROM articles a LEFT JOIN categories c ON c.id = a.category_id
+			  WHERE a.category_id = $1
+			  ORDER BY a.created_at DESC, a.id DESC
+			  LIMIT $2 OFFSET $3`
 
-	var totalItems int64
-	countQuery := `SELECT COUNT(*) FROM articles WHERE category_id = $1`
-	err = database.DB.QueryRow(context.Background(), countQuery, categoryID).Scan(&totalItems)
+	articles, info, err := listPage(ctx, s.db, query, []interface{}{categoryID, limit + 1, offset},
+		`FROM articles WHERE category_id = $1`, []interface{}{categoryID}, limit, offset, count)
 	if err != nil {
-		log.Printf("Error counting articles for category: %v\n", err)
-		return nil, 0, err
+		slog.ErrorContext(ctx, "Error querying articles by category ID", "error", err)
+		return nil, PageInfo{}, err
 	}
-
-	return articles, totalItems, nil
+	return articles, info, nil
 }
 // ... 其他 import 和函数 ...
 
 // SearchArticles performs a full-text search on the articles table.
 // SearchArticles now supports pagination.
-func SearchArticles(query string, limit, offset int) ([]models.Article, int64, error) {
-	sqlQuery := `SELECT id, title, content, category_id, author, source, created_at, updated_at,
-				 ts_rank(content_tsv, plainto_tsquery('simple', $1)) as rank
-				 FROM articles
-				 WHERE content_tsv @@ plainto_tsquery('simple', $1)
-				 ORDER BY rank DESC
-				 LIMIT $2 OFFSET $3`
-	
-	rows, err := database.DB.Query(context.Background(), sqlQuery, query, limit, offset)
-	// ... (scan logic is identical to previous search) ...
-	if err != nil { log.Printf("Error searching articles: %v\n", err); return nil, 0, err }
-	defer rows.Close()
-	var articles []models.Article
-	for rows.Next() {
-		var article models.Article
-		var categoryID sql.NullInt64
-		var rank float32
-		if err := rows.Scan(&article.ID, &article.Title, &article.Content, &categoryID, &article.Author, &article.Source, &article.CreatedAt, &article.UpdatedAt, &rank); err != nil {
-			log.Printf("Error scanning searched article row: %v\n", err); return nil, 0, err
-		}
-        if categoryID.Valid { article.CategoryID = models.NullInt64{Int64: categoryID.Int64, Valid: true} }
-		articles = append(articles, article)
+func (s *PostgresStore) SearchArticles(ctx context.Context, query string, fields []string, limit, offset int, count CountMode) ([]models.ArticleSummary, PageInfo, error) {
+	ctx, done := instrument(ctx, "SearchArticles")
+	defer done()
+	selectList, err := articleSelectList(fields)
+	if err != nil {
+		return nil, PageInfo{}, err
 	}
 
-	var totalItems int64
-	countQuery := `SELECT COUNT(*) FROM articles WHERE content_tsv @@ plainto_tsquery('simple', $1)`
-	err = database.DB.QueryRow(context.Background(), countQuery, query).Scan(&totalItems)
+	sqlQuery := `SELECT ` + selectList + `
+				 FROM articles a LEFT JOIN categories c ON c.id = a.category_id
+				 WHERE a.content_tsv @@ plainto_tsquery('simple', $1)
+				 ORDER BY ts_rank(a.content_tsv, plainto_tsquery('simple', $1)) DESC, a.id DESC
+				 LIMIT $2 OFFSET $3`
+
+	articles, info, err := listPage(ctx, s.db, sqlQuery, []interface{}{query, limit + 1, offset},
+		`FROM articles WHERE content_tsv @@ plainto_tsquery('simple', $1)`, []interface{}{query}, limit, offset, count)
 	if err != nil {
-		log.Printf("Error counting search results: %v\n", err)
-		return nil, 0, err
+		slog.ErrorContext(ctx, "Error searching articles", "error", err)
+		return nil, PageInfo{}, err
 	}
-
-	return articles, totalItems, nil
+	return articles, info, nil
 }
 // ... (package and imports) ...
 
 // --- CUD Functions for Admin ---
 
 // CreateArticle inserts a new article into the database and returns its ID.
-func CreateArticle(article models.Article) (int64, error) {
-	query := `INSERT INTO articles (title, content, category_id, author, source) 
-			  VALUES ($1, $2, $3, $4, $5) RETURNING id`
+// A redirect kept for the article's slug is dropped, as the slug now
+// belongs to the new article.
+func (s *PostgresStore) CreateArticle(ctx context.Context, article models.Article) (int64, error) {
+	ctx, done := instrument(ctx, "CreateArticle")
+	defer done()
+	query := `INSERT INTO articles (title, slug, content, content_format, content_html, toc, excerpt, word_count, char_count, category_id, author, source) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`
 	var articleID int64
 	
 	// Use NullInt64 for nullable category_id
@@ -179,20 +168,41 @@ func CreateArticle(article models.Article) (int64, error) {
 		categoryID.Valid = true
 	}
 
-	err := database.DB.QueryRow(context.Background(), query,
-		article.Title, article.Content, categoryID, article.Author, article.Source).Scan(&articleID)
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		log.Printf("Error creating article: %v", err)
 		return 0, err
 	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, query,
+		article.Title, article.Slug, article.Content, article.ContentFormat, article.ContentHTML, article.TOC,
+		article.Excerpt, article.WordCount, article.CharCount, categoryID, article.Author, article.Source).Scan(&articleID)
+	if err == nil {
+		err = articleSlugs.claim(ctx, tx, article.Slug)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error creating article", "error", err)
+		return 0, translate(err, "article")
+	}
 	return articleID, nil
 }
 
-// UpdateArticle updates an existing article in the database.
-func UpdateArticle(article models.Article) error {
+// UpdateArticle updates an existing article in the database and increments
+// its version. When article.Version is set, the update fails with
+// ErrVersionMismatch unless the stored article is still at that version.
+// An empty slug keeps the current one; when the slug changes, the old one
+// is kept as a redirect to the article.
+func (s *PostgresStore) UpdateArticle(ctx context.Context, article models.Article) error {
+	ctx, done := instrument(ctx, "UpdateArticle")
+	defer done()
 	query := `UPDATE articles 
-			  SET title = $1, content = $2, category_id = $3, author = $4, source = $5, updated_at = now()
-			  WHERE id = $6`
+			  SET title = $1, slug = $2, content = $3, content_format = $4, content_html = $5, toc = $6,
+			      excerpt = $7, word_count = $8, char_count = $9, category_id = $10, author = $11, source = $12, updated_at = now(),
+			      version = version + 1
+			  WHERE id = $13`
 			  
 	var categoryID sql.NullInt64
 	if article.CategoryID.Valid {
@@ -200,22 +210,99 @@ func UpdateArticle(article models.Article) error {
 		categoryID.Valid = true
 	}
 
-	_, err := database.DB.Exec(context.Background(), query,
-		article.Title, article.Content, categoryID, article.Author, article.Source, article.ID)
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	oldSlug, err := articleSlugs.lockForUpdate(ctx, tx, article.ID, article.Version)
 	if err != nil {
-		log.Printf("Error updating article: %v", err)
+		return err
+	}
+	if article.Slug == "" {
+		article.Slug = oldSlug
+	}
+
+	_, err = tx.Exec(ctx, query,
+		article.Title, article.Slug, article.Content, article.ContentFormat, article.ContentHTML, article.TOC,
+		article.Excerpt, article.WordCount, article.CharCount, categoryID, article.Author, article.Source, article.ID)
+	if err == nil {
+		err = articleSlugs.rename(ctx, tx, article.ID, oldSlug, article.Slug)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
 	}
-	return err
+	if err != nil {
+		slog.ErrorContext(ctx, "Error updating article", "error", err)
+		return translate(err, "article")
+	}
+	return nil
 }
 
 // DeleteArticle removes an article from the database by its ID.
-func DeleteArticle(id int64) error {
+func (s *PostgresStore) DeleteArticle(ctx context.Context, id int64) error {
+	ctx, done := instrument(ctx, "DeleteArticle")
+	defer done()
 	query := `DELETE FROM articles WHERE id = $1`
-	_, err := database.DB.Exec(context.Background(), query, id)
+	tag, err := s.db.Exec(ctx, query, id)
 	if err != nil {
-		log.Printf("Error deleting article: %v", err)
+		slog.ErrorContext(ctx, "Error deleting article", "error", err)
+		return translate(err, "article")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("article")
+	}
+	return nil
+}
+
+// ... (Existing Read functions like GetAllArticles, GetArticleByID etc. remain unchanged) ...
+
+// searchIndexBatch is the span of ids reindexed per transaction, which
+// bounds how long rows stay locked against concurrent writers.
+const searchIndexBatch = 1000
+
+// RebuildSearchIndex recomputes content_tsv for every article and returns
+// the number of rows updated. Articles are reindexed in batches of ids, each
+// in its own transaction, with zysj.preserve_updated_at set so that the
+// updated_at trigger leaves modification times alone.
+func (s *PostgresStore) RebuildSearchIndex(ctx context.Context) (int64, error) {
+	ctx, done := instrument(ctx, "RebuildSearchIndex")
+	defer done()
+	var maxID int64
+	if err := s.db.QueryRow(ctx, `SELECT coalesce(max(id), 0) FROM articles`).Scan(&maxID); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for from := int64(0); from < maxID; from += searchIndexBatch {
+		n, err := s.reindexBatch(ctx, from, from+searchIndexBatch)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error rebuilding search index", "error", err, "after_id", from)
+			return total, err
+		}
+		total += n
 	}
-	return err
+	return total, nil
 }
 
-// ... (Existing Read functions like GetAllArticles, GetArticleByID etc. remain unchanged) ...
\ No newline at end of file
+// reindexBatch recomputes content_tsv for the articles with from < id <= to.
+func (s *PostgresStore) reindexBatch(ctx context.Context, from, to int64) (int64, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('zysj.preserve_updated_at', 'on', true)`); err != nil {
+		return 0, err
+	}
+	tag, err := tx.Exec(ctx, `UPDATE articles SET content_tsv =
+		setweight(to_tsvector('simple', coalesce(title,'')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(content,'')), 'B')
+		WHERE id > $1 AND id <= $2`, from, to)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), tx.Commit(ctx)
+}
@@ -2,19 +2,22 @@ package repository
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"database/sql" // <--- 添加这一行
-	"github.com/jalikey/zysj-backend/internal/database" // !! 修改为你的模块路径
+	"github.com/jackc/pgx/v5"
 	"github.com/jalikey/zysj-backend/internal/models"   // !! 修改为你的模块路径
 )
 
 // GetAllCategories queries the database and returns all categories.
-func GetAllCategories() ([]models.Category, error) {
-	query := `SELECT id, name, slug, description, parent_id, created_at FROM categories ORDER BY id ASC`
+func (s *PostgresStore) GetAllCategories(ctx context.Context) ([]models.Category, error) {
+	ctx, done := instrument(ctx, "GetAllCategories")
+	defer done()
+	query := `SELECT id, name, slug, description, parent_id, created_at, version FROM categories ORDER BY id ASC`
 
-	rows, err := database.DB.Query(context.Background(), query)
+	rows, err := s.db.Query(ctx, query)
 	if err != nil {
-		log.Printf("Error querying categories: %v\n", err)
+		slog.ErrorContext(ctx, "Error querying categories", "error", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -33,8 +36,9 @@ func GetAllCategories() ([]models.Category, error) {
 			&category.Description,
 			&parentID, // Scan into the nullable type
 			&category.CreatedAt,
+			&category.Version,
 		); err != nil {
-			log.Printf("Error scanning category row: %v\n", err)
+			slog.ErrorContext(ctx, "Error scanning category row", "error", err)
 			return nil, err
 		}
 		
@@ -47,7 +51,7 @@ func GetAllCategories() ([]models.Category, error) {
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("Error after iterating category rows: %v\n", err)
+		slog.ErrorContext(ctx, "Error after iterating category rows", "error", err)
 		return nil, err
 	}
 
@@ -55,13 +59,17 @@ func GetAllCategories() ([]models.Category, error) {
 }
 // ... GetAllCategories() 函数保持不变 ...
 
-// GetCategoryBySlug queries for a single category by its slug.
-func GetCategoryBySlug(slug string) (models.Category, error) {
-	query := `SELECT id, name, slug, description, parent_id, created_at FROM categories WHERE slug = $1`
+// GetCategoryBySlug queries for a single category by its slug. Slugs the
+// category used before being renamed are not matched; see
+// CategorySlugRedirect.
+func (s *PostgresStore) GetCategoryBySlug(ctx context.Context, slug string) (models.Category, error) {
+	ctx, done := instrument(ctx, "GetCategoryBySlug")
+	defer done()
+	query := `SELECT id, name, slug, description, parent_id, created_at, version FROM categories WHERE slug = $1`
 	var category models.Category
 	var parentID sql.NullInt64
 
-	row := database.DB.QueryRow(context.Background(), query, slug)
+	row := s.db.QueryRow(ctx, query, slug)
 	err := row.Scan(
 		&category.ID,
 		&category.Name,
@@ -69,11 +77,14 @@ func GetCategoryBySlug(slug string) (models.Category, error) {
 		&category.Description,
 		&parentID,
 		&category.CreatedAt,
+		&category.Version,
 	)
 
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		slog.ErrorContext(ctx, "Error scanning single category row", "error", err)
+	}
 	if err != nil {
-		log.Printf("Error scanning single category row: %v\n", err)
-		return models.Category{}, err
+		return models.Category{}, translate(err, "category")
 	}
     
     if parentID.Valid {
@@ -86,8 +97,12 @@ func GetCategoryBySlug(slug string) (models.Category, error) {
 
 // --- CUD Functions for Admin ---
 
-// CreateCategory inserts a new category and returns its ID.
-func CreateCategory(category models.Category) (int64, error) {
+// CreateCategory inserts a new category and returns its ID. A redirect kept
+// for the category's slug is dropped, as the slug now belongs to the new
+// category.
+func (s *PostgresStore) CreateCategory(ctx context.Context, category models.Category) (int64, error) {
+	ctx, done := instrument(ctx, "CreateCategory")
+	defer done()
 	query := `INSERT INTO categories (name, slug, description, parent_id)
 			  VALUES ($1, $2, $3, $4) RETURNING id`
 	var categoryID int64
@@ -97,20 +112,38 @@ func CreateCategory(category models.Category) (int64, error) {
 		parentID.Int64 = category.ParentID.Int64
 		parentID.Valid = true
 	}
-	
-	err := database.DB.QueryRow(context.Background(), query,
-		category.Name, category.Slug, category.Description, parentID).Scan(&categoryID)
+
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		log.Printf("Error creating category: %v", err)
 		return 0, err
 	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, query,
+		category.Name, category.Slug, category.Description, parentID).Scan(&categoryID)
+	if err == nil {
+		err = categorySlugs.claim(ctx, tx, category.Slug)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error creating category", "error", err)
+		return 0, translate(err, "category")
+	}
 	return categoryID, nil
 }
 
-// UpdateCategory updates an existing category.
-func UpdateCategory(category models.Category) error {
+// UpdateCategory updates an existing category and increments its version.
+// When category.Version is set, the update fails with ErrVersionMismatch
+// unless the stored category is still at that version. An empty slug keeps
+// the current one; when the slug changes, the old one is kept as a redirect
+// to the category.
+func (s *PostgresStore) UpdateCategory(ctx context.Context, category models.Category) error {
+	ctx, done := instrument(ctx, "UpdateCategory")
+	defer done()
 	query := `UPDATE categories 
-			  SET name = $1, slug = $2, description = $3, parent_id = $4
+			  SET name = $1, slug = $2, description = $3, parent_id = $4, version = version + 1
 			  WHERE id = $5`
 
 	var parentID sql.NullInt64
@@ -119,22 +152,49 @@ func UpdateCategory(category models.Category) error {
 		parentID.Valid = true
 	}
 
-	_, err := database.DB.Exec(context.Background(), query,
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	oldSlug, err := categorySlugs.lockForUpdate(ctx, tx, category.ID, category.Version)
+	if err != nil {
+		return err
+	}
+	if category.Slug == "" {
+		category.Slug = oldSlug
+	}
+
+	_, err = tx.Exec(ctx, query,
 		category.Name, category.Slug, category.Description, parentID, category.ID)
+	if err == nil {
+		err = categorySlugs.rename(ctx, tx, category.ID, oldSlug, category.Slug)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
 	if err != nil {
-		log.Printf("Error updating category: %v", err)
+		slog.ErrorContext(ctx, "Error updating category", "error", err)
+		return translate(err, "category")
 	}
-	return err
+	return nil
 }
 
 // DeleteCategory removes a category by its ID.
-func DeleteCategory(id int64) error {
+func (s *PostgresStore) DeleteCategory(ctx context.Context, id int64) error {
+	ctx, done := instrument(ctx, "DeleteCategory")
+	defer done()
 	query := `DELETE FROM categories WHERE id = $1`
-	_, err := database.DB.Exec(context.Background(), query, id)
+	tag, err := s.db.Exec(ctx, query, id)
 	if err != nil {
-		log.Printf("Error deleting category: %v", err)
+		slog.ErrorContext(ctx, "Error deleting category", "error", err)
+		return translate(err, "category")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("category")
 	}
-	return err
+	return nil
 }
 
 // ... (Existing Read functions remain unchanged) ...
@@ -142,12 +202,14 @@ func DeleteCategory(id int64) error {
 // ... (之前的 CUD 函数) ...
 
 // GetCategoryByID retrieves a single category by its primary key ID.
-func GetCategoryByID(id int64) (models.Category, error) {
-	query := `SELECT id, name, slug, description, parent_id, created_at FROM categories WHERE id = $1`
+func (s *PostgresStore) GetCategoryByID(ctx context.Context, id int64) (models.Category, error) {
+	ctx, done := instrument(ctx, "GetCategoryByID")
+	defer done()
+	query := `SELECT id, name, slug, description, parent_id, created_at, version FROM categories WHERE id = $1`
 	var category models.Category
 	var parentID sql.NullInt64
 
-	row := database.DB.QueryRow(context.Background(), query, id)
+	row := s.db.QueryRow(ctx, query, id)
 	err := row.Scan(
 		&category.ID,
 		&category.Name,
@@ -155,10 +217,11 @@ func GetCategoryByID(id int64) (models.Category, error) {
 		&category.Description,
 		&parentID,
 		&category.CreatedAt,
+		&category.Version,
 	)
 
 	if err != nil {
-		return models.Category{}, err
+		return models.Category{}, translate(err, "category")
 	}
     
     if parentID.Valid {
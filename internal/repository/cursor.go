@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded,
+// or was issued for a different listing.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of the last row of a keyset-paginated page. List
+// endpoints are ordered by (created_at, id); search is ordered by (rank, id).
+// Listing names the listing the cursor was issued for, so that it can't be
+// replayed against another one, where its position means nothing.
+type Cursor struct {
+	Listing   string    `json:"l"`
+	CreatedAt time.Time `json:"t,omitzero"`
+	Rank      float32   `json:"r,omitempty"`
+	ID        int64     `json:"i"`
+}
+
+// allArticlesListing is the listing of every article.
+const allArticlesListing = "articles"
+
+// categoryListing is the listing of the articles of a category.
+func categoryListing(categoryID int64) string {
+	return "category:" + strconv.FormatInt(categoryID, 10)
+}
+
+// searchListing is the listing of search results for query. The query is
+// hashed to keep cursors short.
+func searchListing(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return "search:" + hex.EncodeToString(sum[:8])
+}
+
+// EncodeCursor turns a cursor into the opaque string handed to clients.
+func EncodeCursor(c Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor for listing. An
+// empty string means "start from the beginning" and yields a nil cursor.
+func DecodeCursor(s, listing string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID <= 0 || c.Listing != listing {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
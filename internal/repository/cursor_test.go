@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 8, 30, 0, 123456000, time.UTC)
+
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{"all articles", Cursor{Listing: allArticlesListing, CreatedAt: created, ID: 42}},
+		{"category", Cursor{Listing: categoryListing(7), CreatedAt: created, ID: 1}},
+		{"search", Cursor{Listing: searchListing("中医 养生"), Rank: 0.25, ID: 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(EncodeCursor(tt.cursor), tt.cursor.Listing)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Listing != tt.cursor.Listing || !got.CreatedAt.Equal(tt.cursor.CreatedAt) ||
+				got.Rank != tt.cursor.Rank || got.ID != tt.cursor.ID {
+				t.Errorf("got %+v, want %+v", *got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	valid := EncodeCursor(Cursor{Listing: categoryListing(7), CreatedAt: time.Now(), ID: 3})
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name    string
+		cursor  string
+		listing string
+		wantNil bool
+		wantErr bool
+	}{
+		{"empty starts over", "", allArticlesListing, true, false},
+		{"same listing", valid, categoryListing(7), false, false},
+		{"other category", valid, categoryListing(8), false, true},
+		{"other listing", valid, allArticlesListing, false, true},
+		{"other search", EncodeCursor(Cursor{Listing: searchListing("a"), ID: 1}), searchListing("b"), false, true},
+		{"not base64", "!!!", allArticlesListing, false, true},
+		{"not json", raw("nope"), allArticlesListing, false, true},
+		{"missing id", raw(`{"l":"articles"}`), allArticlesListing, false, true},
+		{"negative id", raw(`{"l":"articles","i":-1}`), allArticlesListing, false, true},
+		{"unbound", raw(`{"i":5}`), allArticlesListing, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(tt.cursor, tt.listing)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCursor) {
+					t.Fatalf("got error %v, want ErrInvalidCursor", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got == nil) != tt.wantNil {
+				t.Errorf("got cursor %v, want nil: %v", got, tt.wantNil)
+			}
+		})
+	}
+}
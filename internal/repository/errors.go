@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Error kinds returned by the stores, independent of the database driver.
+// Test for them with errors.Is; errors.As with *Error gives the details.
+var (
+	// ErrNotFound means the requested record does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means the write would duplicate a unique value.
+	ErrConflict = errors.New("conflict")
+	// ErrValidation means the database rejected a value, e.g. one that is
+	// too long or fails a check constraint.
+	ErrValidation = errors.New("validation failed")
+	// ErrForeignKey means the record references another one that does not
+	// exist.
+	ErrForeignKey = errors.New("referenced record does not exist")
+	// ErrVersionMismatch means the record was updated since the version the
+	// write was based on.
+	ErrVersionMismatch = errors.New("version mismatch")
+)
+
+// Error is a store failure classified as one of the error kinds.
+type Error struct {
+	Kind   error  // ErrNotFound, ErrConflict, ErrValidation, ErrForeignKey or ErrVersionMismatch
+	Entity string // Record type involved: "article", "category", "user" or "API key"
+	Field  string // Field at fault, when known
+	Err    error  // Underlying driver error, if any
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Kind == ErrNotFound:
+		return e.Entity + " not found"
+	case e.Kind == ErrVersionMismatch:
+		return e.Entity + " was modified by another request"
+	case e.Kind == ErrConflict && e.Field != "":
+		return fmt.Sprintf("%s with this %s already exists", e.Entity, e.Field)
+	case e.Kind == ErrForeignKey && e.Field != "":
+		return fmt.Sprintf("%s %s refers to a record that does not exist", e.Entity, e.Field)
+	case e.Field != "":
+		return fmt.Sprintf("%s %s: %v", e.Entity, e.Field, e.Kind)
+	}
+	return fmt.Sprintf("%s: %v", e.Entity, e.Kind)
+}
+
+// Unwrap exposes both the kind and the driver error to errors.Is and
+// errors.As.
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// notFound returns the ErrNotFound error for an entity.
+func notFound(entity string) error {
+	return &Error{Kind: ErrNotFound, Entity: entity}
+}
+
+// PostgreSQL error codes mapped to error kinds.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgNotNullViolation    = "23502"
+	pgCheckViolation      = "23514"
+	pgStringTooLong       = "22001"
+)
+
+// constraintFields names the field guarded by each constraint, so that
+// violations can point at it.
+var constraintFields = map[string]string{
+	"categories_slug_key":           "slug",
+	"categories_parent_id_fkey":     "parent_id",
+	"articles_slug_key":             "slug",
+	"articles_slug_check":           "slug",
+	"articles_category_id_fkey":     "category_id",
+	"articles_content_format_check": "content_format",
+	"users_username_key":            "username",
+}
+
+// translate classifies a driver error raised while working on entity.
+// Errors that don't correspond to an error kind are returned unchanged.
+func translate(err error, entity string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &Error{Kind: ErrNotFound, Entity: entity, Err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	field := constraintFields[pgErr.ConstraintName]
+	if field == "" {
+		field = pgErr.ColumnName
+	}
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return &Error{Kind: ErrConflict, Entity: entity, Field: field, Err: err}
+	case pgForeignKeyViolation:
+		return &Error{Kind: ErrForeignKey, Entity: entity, Field: field, Err: err}
+	case pgNotNullViolation, pgCheckViolation, pgStringTooLong:
+		return &Error{Kind: ErrValidation, Entity: entity, Field: field, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jalikey/zysj-backend/internal/metrics"
+	"github.com/jalikey/zysj-backend/internal/tracing"
+)
+
+// instrument starts a span named after the repository operation, under
+// which the pgx query spans nest and by which they are named, and returns
+// the span context together with a function to be deferred that ends the
+// span and records the operation's duration.
+func instrument(ctx context.Context, operation string) (context.Context, func()) {
+	start := time.Now()
+	ctx, span := tracing.Tracer().Start(tracing.WithStatement(ctx, operation), "repository."+operation)
+	return ctx, func() {
+		span.End()
+		metrics.ObserveQuery(operation, start)
+	}
+}
@@ -0,0 +1,787 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jalikey/zysj-backend/internal/models"
+)
+
+// MemoryStore is an in-memory implementation of every store. It mirrors the
+// behaviour of PostgresStore closely enough to exercise handlers without a
+// database: missing rows yield ErrNotFound, duplicate slugs and usernames
+// yield ErrConflict, references to missing categories yield ErrForeignKey,
+// renames keep the old slug as a redirect, updates based on an outdated
+// version yield ErrVersionMismatch, and deleting a category cascades to its
+// articles.
+type MemoryStore struct {
+	mu                sync.RWMutex
+	articles          map[int64]models.Article
+	categories        map[int64]models.Category
+	users             map[int64]models.User
+	apiKeys           map[int64]models.APIKey
+	articleRedirects  map[string]int64 // Old article slug to article ID
+	categoryRedirects map[string]int64 // Old category slug to category ID
+	lastID            int64
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		articles:          make(map[int64]models.Article),
+		categories:        make(map[int64]models.Category),
+		users:             make(map[int64]models.User),
+		apiKeys:           make(map[int64]models.APIKey),
+		articleRedirects:  make(map[string]int64),
+		categoryRedirects: make(map[string]int64),
+	}
+}
+
+var (
+	_ ArticleStore  = (*MemoryStore)(nil)
+	_ CategoryStore = (*MemoryStore)(nil)
+	_ UserStore     = (*MemoryStore)(nil)
+	_ APIKeyStore   = (*MemoryStore)(nil)
+)
+
+// --- Articles ---
+//
+// The methods below implement ArticleStore, CategoryStore, UserStore and
+// APIKeyStore; see
+// the PostgresStore methods of the same name for their documentation.
+
+func (s *MemoryStore) GetAllArticles(ctx context.Context, fields []string, limit, offset int, count CountMode) ([]models.ArticleSummary, PageInfo, error) {
+	return s.articlePage(ctx, nil, fields, limit, offset, count)
+}
+
+func (s *MemoryStore) GetArticlesAfter(ctx context.Context, fields []string, after string, limit int) ([]models.ArticleSummary, string, error) {
+	return s.articlesAfter(ctx, allArticlesListing, nil, fields, after, limit)
+}
+
+func (s *MemoryStore) GetArticlesByCategoryID(ctx context.Context, categoryID int64, fields []string, limit, offset int, count CountMode) ([]models.ArticleSummary, PageInfo, error) {
+	return s.articlePage(ctx, inCategory(categoryID), fields, limit, offset, count)
+}
+
+func (s *MemoryStore) GetArticlesByCategoryIDAfter(ctx context.Context, categoryID int64, fields []string, after string, limit int) ([]models.ArticleSummary, string, error) {
+	return s.articlesAfter(ctx, categoryListing(categoryID), inCategory(categoryID), fields, after, limit)
+}
+
+func (s *MemoryStore) SearchArticles(ctx context.Context, query string, fields []string, limit, offset int, count CountMode) ([]models.ArticleSummary, PageInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, PageInfo{}, err
+	}
+	if _, err := articleFields(fields); err != nil {
+		return nil, PageInfo{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matches := s.search(query)
+	page := paginate(matches, offset, limit)
+	info := PageInfo{HasMore: offset+len(page) < len(matches), Estimated: count == CountEstimate}
+	if count != CountNone {
+		info.Total = int64(len(matches))
+	}
+
+	rows := make([]models.ArticleSummary, 0, len(page))
+	for _, m := range page {
+		rows = append(rows, s.project(m.article, fields))
+	}
+	return rows, info, nil
+}
+
+func (s *MemoryStore) SearchArticlesAfter(ctx context.Context, query string, fields []string, after string, limit int) ([]models.ArticleSummary, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	listing := searchListing(query)
+	cursor, err := DecodeCursor(after, listing)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := articleFields(fields); err != nil {
+		return nil, "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows := []models.ArticleSummary{}
+	var last searchMatch
+	next := ""
+	for _, m := range s.search(query) {
+		if cursor != nil && !(m.rank < cursor.Rank || (m.rank == cursor.Rank && m.article.ID < cursor.ID)) {
+			continue
+		}
+		if len(rows) == limit {
+			next = EncodeCursor(Cursor{Listing: listing, Rank: last.rank, ID: last.article.ID})
+			break
+		}
+		rows = append(rows, s.project(m.article, fields))
+		last = m
+	}
+	return rows, next, nil
+}
+
+func (s *MemoryStore) GetArticleByID(ctx context.Context, id int64) (models.Article, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Article{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	article, ok := s.articles[id]
+	if !ok {
+		return models.Article{}, notFound("article")
+	}
+	return article, nil
+}
+
+func (s *MemoryStore) GetArticleBySlug(ctx context.Context, slug string) (models.Article, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Article{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, a := range s.articles {
+		if a.Slug == slug {
+			return a, nil
+		}
+	}
+	return models.Article{}, notFound("article")
+}
+
+func (s *MemoryStore) ArticleSlugRedirect(ctx context.Context, slug string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if a, ok := s.articles[s.articleRedirects[slug]]; ok {
+		return a.Slug, nil
+	}
+	return "", notFound("article")
+}
+
+func (s *MemoryStore) UniqueArticleSlug(ctx context.Context, base string, excludeID int64) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var taken []string
+	for _, a := range s.articles {
+		if a.ID != excludeID {
+			taken = append(taken, a.Slug)
+		}
+	}
+	return memoryUniqueSlug(base, "article", taken, s.articleRedirects, excludeID), nil
+}
+
+func (s *MemoryStore) CreateArticle(ctx context.Context, article models.Article) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkArticleSlug(article); err != nil {
+		return 0, err
+	}
+	if err := s.checkCategory("article", "category_id", article.CategoryID); err != nil {
+		return 0, err
+	}
+	delete(s.articleRedirects, article.Slug)
+	s.lastID++
+	now := time.Now()
+	article.ID = s.lastID
+	article.CreatedAt, article.UpdatedAt = now, now
+	article.Version = 1
+	s.articles[article.ID] = article
+	return article.ID, nil
+}
+
+func (s *MemoryStore) UpdateArticle(ctx context.Context, article models.Article) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.articles[article.ID]
+	if !ok {
+		return notFound("article")
+	}
+	if article.Version != 0 && article.Version != existing.Version {
+		return &Error{Kind: ErrVersionMismatch, Entity: "article"}
+	}
+	if article.Slug == "" {
+		article.Slug = existing.Slug
+	}
+	if err := s.checkArticleSlug(article); err != nil {
+		return err
+	}
+	if err := s.checkCategory("article", "category_id", article.CategoryID); err != nil {
+		return err
+	}
+	renameSlug(s.articleRedirects, article.ID, existing.Slug, article.Slug)
+	article.CreatedAt = existing.CreatedAt
+	article.UpdatedAt = time.Now()
+	article.Version = existing.Version + 1
+	s.articles[article.ID] = article
+	return nil
+}
+
+func (s *MemoryStore) DeleteArticle(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.articles[id]; !ok {
+		return notFound("article")
+	}
+	delete(s.articles, id)
+	dropRedirects(s.articleRedirects, id)
+	return nil
+}
+
+func (s *MemoryStore) RebuildSearchIndex(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return int64(len(s.articles)), nil
+}
+
+// --- Categories ---
+
+func (s *MemoryStore) GetAllCategories(ctx context.Context) ([]models.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var categories []models.Category
+	for _, c := range s.categories {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+	return categories, nil
+}
+
+func (s *MemoryStore) GetCategoryByID(ctx context.Context, id int64) (models.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Category{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	category, ok := s.categories[id]
+	if !ok {
+		return models.Category{}, notFound("category")
+	}
+	return category, nil
+}
+
+func (s *MemoryStore) GetCategoryBySlug(ctx context.Context, slug string) (models.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Category{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, c := range s.categories {
+		if c.Slug == slug {
+			return c, nil
+		}
+	}
+	return models.Category{}, notFound("category")
+}
+
+func (s *MemoryStore) CategorySlugRedirect(ctx context.Context, slug string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if c, ok := s.categories[s.categoryRedirects[slug]]; ok {
+		return c.Slug, nil
+	}
+	return "", notFound("category")
+}
+
+func (s *MemoryStore) UniqueCategorySlug(ctx context.Context, base string, excludeID int64) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var taken []string
+	for _, c := range s.categories {
+		if c.ID != excludeID {
+			taken = append(taken, c.Slug)
+		}
+	}
+	return memoryUniqueSlug(base, "category", taken, s.categoryRedirects, excludeID), nil
+}
+
+func (s *MemoryStore) CreateCategory(ctx context.Context, category models.Category) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkCategorySlug(category); err != nil {
+		return 0, err
+	}
+	if err := s.checkCategory("category", "parent_id", category.ParentID); err != nil {
+		return 0, err
+	}
+	delete(s.categoryRedirects, category.Slug)
+	s.lastID++
+	category.ID = s.lastID
+	category.CreatedAt = time.Now()
+	category.Version = 1
+	s.categories[category.ID] = category
+	return category.ID, nil
+}
+
+func (s *MemoryStore) UpdateCategory(ctx context.Context, category models.Category) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.categories[category.ID]
+	if !ok {
+		return notFound("category")
+	}
+	if category.Version != 0 && category.Version != existing.Version {
+		return &Error{Kind: ErrVersionMismatch, Entity: "category"}
+	}
+	if category.Slug == "" {
+		category.Slug = existing.Slug
+	}
+	if err := s.checkCategorySlug(category); err != nil {
+		return err
+	}
+	if err := s.checkCategory("category", "parent_id", category.ParentID); err != nil {
+		return err
+	}
+	renameSlug(s.categoryRedirects, category.ID, existing.Slug, category.Slug)
+	category.CreatedAt = existing.CreatedAt
+	category.Version = existing.Version + 1
+	s.categories[category.ID] = category
+	return nil
+}
+
+func (s *MemoryStore) DeleteCategory(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.categories[id]; !ok {
+		return notFound("category")
+	}
+	delete(s.categories, id)
+	dropRedirects(s.categoryRedirects, id)
+	// Mirror ON DELETE CASCADE on articles and ON DELETE SET NULL on children
+	for articleID, a := range s.articles {
+		if a.CategoryID.Valid && a.CategoryID.Int64 == id {
+			delete(s.articles, articleID)
+			dropRedirects(s.articleRedirects, articleID)
+		}
+	}
+	for childID, c := range s.categories {
+		if c.ParentID.Valid && c.ParentID.Int64 == id {
+			c.ParentID = models.NullInt64{}
+			s.categories[childID] = c
+		}
+	}
+	return nil
+}
+
+// --- Users ---
+
+func (s *MemoryStore) CreateUser(ctx context.Context, user models.User) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Username == user.Username {
+			return 0, &Error{Kind: ErrConflict, Entity: "user", Field: "username"}
+		}
+	}
+	s.lastID++
+	user.ID = s.lastID
+	user.CreatedAt = time.Now()
+	s.users[user.ID] = user
+	return user.ID, nil
+}
+
+func (s *MemoryStore) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return models.User{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return models.User{}, notFound("user")
+}
+
+func (s *MemoryStore) UpdateUserPassword(ctx context.Context, username, passwordHash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, u := range s.users {
+		if u.Username == username {
+			u.PasswordHash = passwordHash
+			s.users[id] = u
+			return nil
+		}
+	}
+	return notFound("user")
+}
+
+// --- API keys ---
+
+func (s *MemoryStore) CreateAPIKey(ctx context.Context, key models.APIKey) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	key.ID = s.lastID
+	key.CreatedAt = time.Now()
+	key.Scopes = slices.Clone(key.Scopes)
+	s.apiKeys[key.ID] = key
+	return key.ID, nil
+}
+
+func (s *MemoryStore) GetAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return models.APIKey{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, k := range s.apiKeys {
+		if k.KeyHash == hash {
+			return k, nil
+		}
+	}
+	return models.APIKey{}, notFound("API key")
+}
+
+func (s *MemoryStore) GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]models.APIKey, 0, len(s.apiKeys))
+	for _, k := range s.apiKeys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID > keys[j].ID })
+	return keys, nil
+}
+
+func (s *MemoryStore) RevokeAPIKey(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.apiKeys[id]
+	if !ok {
+		return notFound("API key")
+	}
+	if k.RevokedAt == nil {
+		now := time.Now()
+		k.RevokedAt = &now
+		s.apiKeys[id] = k
+	}
+	return nil
+}
+
+func (s *MemoryStore) TouchAPIKey(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if k, ok := s.apiKeys[id]; ok {
+		now := time.Now()
+		k.LastUsedAt = &now
+		s.apiKeys[id] = k
+	}
+	return nil
+}
+
+// --- Helpers ---
+
+// searchMatch is an article matched by a search together with its rank.
+type searchMatch struct {
+	article models.Article
+	rank    float32
+}
+
+// inCategory returns a filter selecting the articles of one category.
+func inCategory(categoryID int64) func(models.Article) bool {
+	return func(a models.Article) bool {
+		return a.CategoryID.Valid && a.CategoryID.Int64 == categoryID
+	}
+}
+
+// sortedArticles returns the articles accepted by filter, newest first.
+// The caller must hold the read lock.
+func (s *MemoryStore) sortedArticles(filter func(models.Article) bool) []models.Article {
+	var articles []models.Article
+	for _, a := range s.articles {
+		if filter == nil || filter(a) {
+			articles = append(articles, a)
+		}
+	}
+	sort.Slice(articles, func(i, j int) bool {
+		if !articles[i].CreatedAt.Equal(articles[j].CreatedAt) {
+			return articles[i].CreatedAt.After(articles[j].CreatedAt)
+		}
+		return articles[i].ID > articles[j].ID
+	})
+	return articles
+}
+
+func (s *MemoryStore) articlePage(ctx context.Context, filter func(models.Article) bool, fields []string, limit, offset int, count CountMode) ([]models.ArticleSummary, PageInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, PageInfo{}, err
+	}
+	if _, err := articleFields(fields); err != nil {
+		return nil, PageInfo{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	articles := s.sortedArticles(filter)
+	page := paginate(articles, offset, limit)
+	info := PageInfo{HasMore: offset+len(page) < len(articles), Estimated: count == CountEstimate}
+	if count != CountNone {
+		info.Total = int64(len(articles))
+	}
+
+	rows := make([]models.ArticleSummary, 0, len(page))
+	for _, a := range page {
+		rows = append(rows, s.project(a, fields))
+	}
+	return rows, info, nil
+}
+
+func (s *MemoryStore) articlesAfter(ctx context.Context, listing string, filter func(models.Article) bool, fields []string, after string, limit int) ([]models.ArticleSummary, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	cursor, err := DecodeCursor(after, listing)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := articleFields(fields); err != nil {
+		return nil, "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows := []models.ArticleSummary{}
+	var last models.Article
+	next := ""
+	for _, a := range s.sortedArticles(filter) {
+		if cursor != nil && !(a.CreatedAt.Before(cursor.CreatedAt) ||
+			(a.CreatedAt.Equal(cursor.CreatedAt) && a.ID < cursor.ID)) {
+			continue
+		}
+		if len(rows) == limit {
+			next = EncodeCursor(Cursor{Listing: listing, CreatedAt: last.CreatedAt, ID: last.ID})
+			break
+		}
+		rows = append(rows, s.project(a, fields))
+		last = a
+	}
+	return rows, next, nil
+}
+
+// search returns the articles matching every term of the query, best match
+// first. The rank counts term occurrences, with title matches weighted
+// higher, roughly like the setweight calls behind content_tsv. The caller
+// must hold the read lock.
+func (s *MemoryStore) search(query string) []searchMatch {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var matches []searchMatch
+	for _, a := range s.articles {
+		title, body := strings.ToLower(a.Title), strings.ToLower(a.Content)
+		var rank float32
+		matched := true
+		for _, t := range terms {
+			n := strings.Count(title, t)*4 + strings.Count(body, t)
+			if n == 0 {
+				matched = false
+				break
+			}
+			rank += float32(n)
+		}
+		if matched {
+			matches = append(matches, searchMatch{article: a, rank: rank})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank > matches[j].rank
+		}
+		return matches[i].article.ID > matches[j].article.ID
+	})
+	return matches
+}
+
+// project returns the summary of an article with the requested fields, as
+// the list queries build it. The caller must hold the read lock and have
+// checked fields with articleFields.
+func (s *MemoryStore) project(a models.Article, fields []string) models.ArticleSummary {
+	projection, _ := articleFields(fields)
+	summary := models.ArticleSummary{
+		ID:            a.ID,
+		Title:         a.Title,
+		Slug:          a.Slug,
+		Content:       a.Content,
+		ContentFormat: a.ContentFormat,
+		Excerpt:       a.Excerpt,
+		WordCount:     a.WordCount,
+		CharCount:     a.CharCount,
+		CreatedAt:     a.CreatedAt,
+		UpdatedAt:     a.UpdatedAt,
+		CategoryID:    a.CategoryID,
+		Author:        models.NullString{String: a.Author, Valid: true},
+		Source:        models.NullString{String: a.Source, Valid: true},
+		Fields:        projection,
+	}
+	if c, ok := s.categories[a.CategoryID.Int64]; ok && a.CategoryID.Valid {
+		summary.CategoryName = models.NullString{String: c.Name, Valid: true}
+	}
+	return summary
+}
+
+// checkCategory returns ErrForeignKey when the field of entity refers to a
+// category that does not exist. The caller must hold the lock.
+func (s *MemoryStore) checkCategory(entity, field string, id models.NullInt64) error {
+	if !id.Valid {
+		return nil
+	}
+	if _, ok := s.categories[id.Int64]; !ok {
+		return &Error{Kind: ErrForeignKey, Entity: entity, Field: field}
+	}
+	return nil
+}
+
+// checkCategorySlug returns ErrConflict when another category already uses
+// the slug. The caller must hold the lock.
+func (s *MemoryStore) checkCategorySlug(category models.Category) error {
+	for _, c := range s.categories {
+		if c.Slug == category.Slug && c.ID != category.ID {
+			return &Error{Kind: ErrConflict, Entity: "category", Field: "slug"}
+		}
+	}
+	return nil
+}
+
+// checkArticleSlug returns ErrValidation for an empty slug, mirroring
+// articles_slug_check, and ErrConflict when another article already uses
+// the slug. The caller must hold the lock.
+func (s *MemoryStore) checkArticleSlug(article models.Article) error {
+	if article.Slug == "" {
+		return &Error{Kind: ErrValidation, Entity: "article", Field: "slug"}
+	}
+	for _, a := range s.articles {
+		if a.Slug == article.Slug && a.ID != article.ID {
+			return &Error{Kind: ErrConflict, Entity: "article", Field: "slug"}
+		}
+	}
+	return nil
+}
+
+// memoryUniqueSlug is slugTable.uniqueSlug over the in-memory records:
+// taken lists the slugs of the other records.
+func memoryUniqueSlug(base, entity string, taken []string, redirects map[string]int64, excludeID int64) string {
+	if base == "" {
+		base = entity
+	}
+	for slug, id := range redirects {
+		if id != excludeID {
+			taken = append(taken, slug)
+		}
+	}
+	return pickSlug(base, taken)
+}
+
+// renameSlug keeps the old slug of a renamed record as a redirect to it,
+// and drops any redirect for the slug it now uses.
+func renameSlug(redirects map[string]int64, id int64, from, to string) {
+	if from == to {
+		return
+	}
+	delete(redirects, to)
+	redirects[from] = id
+}
+
+// dropRedirects removes the redirects to a deleted record.
+func dropRedirects(redirects map[string]int64, id int64) {
+	for slug, target := range redirects {
+		if target == id {
+			delete(redirects, slug)
+		}
+	}
+}
+
+// paginate returns the slice of items for the given offset and limit.
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jalikey/zysj-backend/internal/models"
+)
+
+// CountMode selects how a paginated list computes its total.
+type CountMode int
+
+const (
+	// CountExact runs COUNT(*) in the same snapshot as the page query.
+	CountExact CountMode = iota
+	// CountEstimate reads the planner's row estimate instead of counting.
+	CountEstimate
+	// CountNone skips the total; clients rely on PageInfo.HasMore.
+	CountNone
+)
+
+// PageInfo describes the page returned by a paginated list query.
+type PageInfo struct {
+	Total     int64 // Not set when the count mode is CountNone
+	Estimated bool  // Total comes from planner statistics
+	HasMore   bool  // Another page follows this one
+}
+
+// querier is implemented by both the pool and transactions.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// listPage runs an offset-paginated list query and computes its total.
+//
+// pageQuery must end in "LIMIT $n OFFSET $m" with limit+1 and offset as its
+// last two arguments; the extra row tells whether another page follows.
+// countFrom is the FROM/WHERE part shared by the count, taking countArgs.
+// Exact counts run in the same read-only repeatable-read transaction as the
+// page query so that the total always agrees with the data.
+func listPage(ctx context.Context, db *pgxpool.Pool, pageQuery string, pageArgs []interface{}, countFrom string, countArgs []interface{}, limit, offset int, mode CountMode) ([]models.ArticleSummary, PageInfo, error) {
+	var info PageInfo
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, info, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := queryArticleRows(ctx, tx, pageQuery, pageArgs...)
+	if err != nil {
+		return nil, info, err
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		info.HasMore = true
+	}
+
+	switch mode {
+	case CountExact:
+		if !info.HasMore && (len(rows) > 0 || offset == 0) {
+			// The last page tells us the total without counting
+			info.Total = int64(offset + len(rows))
+			break
+		}
+		err = tx.QueryRow(ctx, `SELECT COUNT(*) `+countFrom, countArgs...).Scan(&info.Total)
+	case CountEstimate:
+		info.Total, err = estimateCount(ctx, tx, `SELECT 1 `+countFrom, countArgs...)
+		info.Estimated = true
+	}
+	if err != nil {
+		return nil, info, err
+	}
+
+	return summaries(rows), info, tx.Commit(ctx)
+}
+
+// estimateCount returns the planner's estimate of the number of rows the
+// query would produce, which is read from table statistics instead of
+// scanning the table.
+func estimateCount(ctx context.Context, q querier, query string, args ...interface{}) (int64, error) {
+	var raw string
+	if err := q.QueryRow(ctx, `EXPLAIN (FORMAT JSON) `+query, args...).Scan(&raw); err != nil {
+		return 0, err
+	}
+	var plan []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(raw), &plan); err != nil {
+		return 0, err
+	}
+	if len(plan) == 0 {
+		return 0, errors.New("empty query plan")
+	}
+	return int64(plan[0].Plan.Rows), nil
+}
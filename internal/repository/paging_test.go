@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// planQuerier answers QueryRow with a canned EXPLAIN output.
+type planQuerier struct {
+	plan  string
+	err   error
+	query string
+}
+
+func (q *planQuerier) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (q *planQuerier) QueryRow(_ context.Context, sql string, _ ...interface{}) pgx.Row {
+	q.query = sql
+	return q
+}
+
+func (q *planQuerier) Scan(dest ...interface{}) error {
+	if q.err != nil {
+		return q.err
+	}
+	*dest[0].(*string) = q.plan
+	return nil
+}
+
+func TestEstimateCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    string
+		err     error
+		want    int64
+		wantErr bool
+	}{
+		{"estimate", `[{"Plan": {"Node Type": "Seq Scan", "Plan Rows": 12345.0}}]`, nil, 12345, false},
+		{"fractional rows", `[{"Plan": {"Plan Rows": 2.6}}]`, nil, 2, false},
+		{"empty plan", `[]`, nil, 0, true},
+		{"not json", `Seq Scan on articles`, nil, 0, true},
+		{"query error", "", errors.New("connection reset"), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &planQuerier{plan: tt.plan, err: tt.err}
+			got, err := estimateCount(context.Background(), q, "SELECT 1 FROM articles a")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("estimateCount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("estimateCount() = %d, want %d", got, tt.want)
+			}
+			if !strings.HasPrefix(q.query, "EXPLAIN (FORMAT JSON) SELECT 1 FROM articles a") {
+				t.Errorf("ran %q, want the query explained", q.query)
+			}
+		})
+	}
+}
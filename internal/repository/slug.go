@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// slugTable describes a table whose rows are addressed by slug and the
+// table recording the slugs they used before being renamed.
+type slugTable struct {
+	entity    string // Record type, also the fallback slug
+	table     string
+	redirects string
+	key       string // Column of redirects referencing table
+}
+
+var (
+	articleSlugs  = slugTable{entity: "article", table: "articles", redirects: "article_slug_redirects", key: "article_id"}
+	categorySlugs = slugTable{entity: "category", table: "categories", redirects: "category_slug_redirects", key: "category_id"}
+)
+
+// uniqueSlug returns base, or base with the lowest numeric suffix that makes
+// it unique, ignoring the record excludeID. Slugs kept for redirects count
+// as taken so that old URLs don't start pointing at another record. An
+// empty base falls back to the entity name.
+func (t slugTable) uniqueSlug(ctx context.Context, q querier, base string, excludeID int64) (string, error) {
+	if base == "" {
+		base = t.entity
+	}
+	query := `SELECT slug FROM ` + t.table + ` WHERE (slug = $1 OR slug LIKE $2) AND id <> $3
+			  UNION
+			  SELECT slug FROM ` + t.redirects + ` WHERE (slug = $1 OR slug LIKE $2) AND ` + t.key + ` <> $3`
+	rows, err := q.Query(ctx, query, base, base+"-%", excludeID)
+	if err != nil {
+		return "", err
+	}
+	slugs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return "", err
+	}
+	return pickSlug(base, slugs), nil
+}
+
+// pickSlug returns base, or base-2, base-3... whichever is first not taken.
+func pickSlug(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, s := range taken {
+		used[s] = true
+	}
+	candidate := base
+	for i := 2; used[candidate]; i++ {
+		candidate = base + "-" + strconv.Itoa(i)
+	}
+	return candidate
+}
+
+// redirect returns the current slug of the record that used slug before
+// being renamed, or ErrNotFound.
+func (t slugTable) redirect(ctx context.Context, q querier, slug string) (string, error) {
+	query := `SELECT t.slug FROM ` + t.redirects + ` r JOIN ` + t.table + ` t ON t.id = r.` + t.key + `
+			  WHERE r.slug = $1`
+	var current string
+	if err := q.QueryRow(ctx, query, slug).Scan(&current); err != nil {
+		return "", translate(err, t.entity)
+	}
+	return current, nil
+}
+
+// claim drops the redirect for a slug that a record has just taken.
+func (t slugTable) claim(ctx context.Context, tx pgx.Tx, slug string) error {
+	_, err := tx.Exec(ctx, `DELETE FROM `+t.redirects+` WHERE slug = $1`, slug)
+	return err
+}
+
+// rename records that record id moved from slug from to slug to.
+func (t slugTable) rename(ctx context.Context, tx pgx.Tx, id int64, from, to string) error {
+	if from == to {
+		return nil
+	}
+	if err := t.claim(ctx, tx, to); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `INSERT INTO `+t.redirects+` (slug, `+t.key+`) VALUES ($1, $2)
+		ON CONFLICT (slug) DO UPDATE SET `+t.key+` = EXCLUDED.`+t.key+`, created_at = now()`, from, id)
+	return err
+}
+
+// lockForUpdate locks record id for the rest of the transaction and returns
+// its slug. It fails with ErrVersionMismatch when version is set and the
+// record is at another version.
+func (t slugTable) lockForUpdate(ctx context.Context, tx pgx.Tx, id, version int64) (string, error) {
+	var slug string
+	var current int64
+	err := tx.QueryRow(ctx, `SELECT slug, version FROM `+t.table+` WHERE id = $1 FOR UPDATE`, id).Scan(&slug, &current)
+	if err != nil {
+		return "", translate(err, t.entity)
+	}
+	if version != 0 && version != current {
+		return "", &Error{Kind: ErrVersionMismatch, Entity: t.entity}
+	}
+	return slug, nil
+}
+
+// UniqueArticleSlug returns base, suffixed if needed to be unique among the
+// articles other than excludeID.
+func (s *PostgresStore) UniqueArticleSlug(ctx context.Context, base string, excludeID int64) (string, error) {
+	ctx, done := instrument(ctx, "UniqueArticleSlug")
+	defer done()
+	return articleSlugs.uniqueSlug(ctx, s.db, base, excludeID)
+}
+
+// ArticleSlugRedirect returns the current slug of the article that was
+// previously reachable under slug.
+func (s *PostgresStore) ArticleSlugRedirect(ctx context.Context, slug string) (string, error) {
+	ctx, done := instrument(ctx, "ArticleSlugRedirect")
+	defer done()
+	return articleSlugs.redirect(ctx, s.db, slug)
+}
+
+// UniqueCategorySlug returns base, suffixed if needed to be unique among
+// the categories other than excludeID.
+func (s *PostgresStore) UniqueCategorySlug(ctx context.Context, base string, excludeID int64) (string, error) {
+	ctx, done := instrument(ctx, "UniqueCategorySlug")
+	defer done()
+	return categorySlugs.uniqueSlug(ctx, s.db, base, excludeID)
+}
+
+// CategorySlugRedirect returns the current slug of the category that was
+// previously reachable under slug.
+func (s *PostgresStore) CategorySlugRedirect(ctx context.Context, slug string) (string, error) {
+	ctx, done := instrument(ctx, "CategorySlugRedirect")
+	defer done()
+	return categorySlugs.redirect(ctx, s.db, slug)
+}
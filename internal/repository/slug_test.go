@@ -0,0 +1,27 @@
+package repository
+
+import "testing"
+
+func TestPickSlug(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  string
+		taken []string
+		want  string
+	}{
+		{"free", "herbs", nil, "herbs"},
+		{"unrelated taken", "herbs", []string{"herbs-guide"}, "herbs"},
+		{"base taken", "herbs", []string{"herbs"}, "herbs-2"},
+		{"suffixes taken", "herbs", []string{"herbs", "herbs-2", "herbs-3"}, "herbs-4"},
+		{"gap reused", "herbs", []string{"herbs", "herbs-3"}, "herbs-2"},
+		{"only suffix taken", "herbs", []string{"herbs-2"}, "herbs"},
+		{"base ending in number", "top-10", []string{"top-10"}, "top-10-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickSlug(tt.base, tt.taken); got != tt.want {
+				t.Errorf("pickSlug(%q, %v) = %q, want %q", tt.base, tt.taken, got, tt.want)
+			}
+		})
+	}
+}
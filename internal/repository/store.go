@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jalikey/zysj-backend/internal/models"
+)
+
+// ArticleStore reads and writes articles. Lookups, updates and deletes of
+// a missing article return ErrNotFound.
+type ArticleStore interface {
+	GetAllArticles(ctx context.Context, fields []string, limit, offset int, count CountMode) ([]models.ArticleSummary, PageInfo, error)
+	GetArticlesAfter(ctx context.Context, fields []string, after string, limit int) ([]models.ArticleSummary, string, error)
+	GetArticlesByCategoryID(ctx context.Context, categoryID int64, fields []string, limit, offset int, count CountMode) ([]models.ArticleSummary, PageInfo, error)
+	GetArticlesByCategoryIDAfter(ctx context.Context, categoryID int64, fields []string, after string, limit int) ([]models.ArticleSummary, string, error)
+	SearchArticles(ctx context.Context, query string, fields []string, limit, offset int, count CountMode) ([]models.ArticleSummary, PageInfo, error)
+	SearchArticlesAfter(ctx context.Context, query string, fields []string, after string, limit int) ([]models.ArticleSummary, string, error)
+	GetArticleByID(ctx context.Context, id int64) (models.Article, error)
+	GetArticleBySlug(ctx context.Context, slug string) (models.Article, error)
+	ArticleSlugRedirect(ctx context.Context, slug string) (string, error)
+	UniqueArticleSlug(ctx context.Context, base string, excludeID int64) (string, error)
+	CreateArticle(ctx context.Context, article models.Article) (int64, error)
+	UpdateArticle(ctx context.Context, article models.Article) error
+	DeleteArticle(ctx context.Context, id int64) error
+	RebuildSearchIndex(ctx context.Context) (int64, error)
+}
+
+// CategoryStore reads and writes categories. Lookups, updates and deletes
+// of a missing category return ErrNotFound.
+type CategoryStore interface {
+	GetAllCategories(ctx context.Context) ([]models.Category, error)
+	GetCategoryByID(ctx context.Context, id int64) (models.Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (models.Category, error)
+	CategorySlugRedirect(ctx context.Context, slug string) (string, error)
+	UniqueCategorySlug(ctx context.Context, base string, excludeID int64) (string, error)
+	CreateCategory(ctx context.Context, category models.Category) (int64, error)
+	UpdateCategory(ctx context.Context, category models.Category) error
+	DeleteCategory(ctx context.Context, id int64) error
+}
+
+// UserStore reads and writes users.
+type UserStore interface {
+	CreateUser(ctx context.Context, user models.User) (int64, error)
+	GetUserByUsername(ctx context.Context, username string) (models.User, error)
+	UpdateUserPassword(ctx context.Context, username, passwordHash string) error
+}
+
+// APIKeyStore reads and writes API keys. Lookups and revocations of a
+// missing key return ErrNotFound.
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, key models.APIKey) (int64, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error)
+	GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+	TouchAPIKey(ctx context.Context, id int64) error
+}
+
+// PostgresStore implements every store on top of a pgx connection pool.
+type PostgresStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresStore returns a store that runs its queries on db.
+func NewPostgresStore(db *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+var (
+	_ ArticleStore  = (*PostgresStore)(nil)
+	_ CategoryStore = (*PostgresStore)(nil)
+	_ UserStore     = (*PostgresStore)(nil)
+	_ APIKeyStore   = (*PostgresStore)(nil)
+)
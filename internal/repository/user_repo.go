@@ -2,34 +2,53 @@ package repository
 
 import (
 	"context"
-	"log"
+	"log/slog"
 
-	"github.com/jalikey/zysj-backend/internal/database"
 	"github.com/jalikey/zysj-backend/internal/models"
 )
 
 // CreateUser inserts a new user into the database.
-func CreateUser(user models.User) (int64, error) {
+func (s *PostgresStore) CreateUser(ctx context.Context, user models.User) (int64, error) {
+	ctx, done := instrument(ctx, "CreateUser")
+	defer done()
 	var userID int64
 	query := `INSERT INTO users (username, password_hash) VALUES ($1, $2) RETURNING id`
-	err := database.DB.QueryRow(context.Background(), query, user.Username, user.PasswordHash).Scan(&userID)
+	err := s.db.QueryRow(ctx, query, user.Username, user.PasswordHash).Scan(&userID)
 	if err != nil {
-		log.Printf("Error creating user: %v", err)
-		return 0, err
+		slog.ErrorContext(ctx, "Error creating user", "error", err)
+		return 0, translate(err, "user")
 	}
 	return userID, nil
 }
 
 // GetUserByUsername finds a user by their username.
-func GetUserByUsername(username string) (models.User, error) {
+func (s *PostgresStore) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	ctx, done := instrument(ctx, "GetUserByUsername")
+	defer done()
 	var user models.User
 	query := `SELECT id, username, password_hash, created_at FROM users WHERE username = $1`
-	err := database.DB.QueryRow(context.Background(), query, username).Scan(
+	err := s.db.QueryRow(ctx, query, username).Scan(
 		&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt,
 	)
 	if err != nil {
 		// It's common for this query to find no rows, which is not a server error
-		return models.User{}, err
+		return models.User{}, translate(err, "user")
 	}
 	return user, nil
-}
\ No newline at end of file
+}
+// UpdateUserPassword replaces the password hash of an existing user.
+// It returns ErrNotFound when no user has that username.
+func (s *PostgresStore) UpdateUserPassword(ctx context.Context, username, passwordHash string) error {
+	ctx, done := instrument(ctx, "UpdateUserPassword")
+	defer done()
+	query := `UPDATE users SET password_hash = $1 WHERE username = $2`
+	tag, err := s.db.Exec(ctx, query, passwordHash, username)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error updating user password", "error", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("user")
+	}
+	return nil
+}
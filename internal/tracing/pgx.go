@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer is a pgx.QueryTracer that records a client span for every
+// query. Spans are named after the statement keyword and, when known, the
+// operation running the query, e.g. "SELECT GetArticleByID". Argument values
+// are never recorded, as they may hold passwords or personal data.
+type QueryTracer struct {
+	// QueryText also records the SQL text of each query. It is off by
+	// default: the text is long and mostly duplicates the operation name.
+	QueryText bool
+}
+
+var _ pgx.QueryTracer = QueryTracer{}
+
+// statementKey holds the name queries are traced under.
+type statementKey struct{}
+
+// WithStatement returns a context whose queries are traced under name,
+// such as the repository operation running them.
+func WithStatement(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, statementKey{}, name)
+}
+
+// TraceQueryStart starts the span for a query.
+func (t QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	op := operation(data.SQL)
+	name := op
+	if statement, ok := ctx.Value(statementKey{}).(string); ok {
+		name += " " + statement
+	}
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(op),
+	}
+	if t.QueryText {
+		attrs = append(attrs, semconv.DBQueryText(data.SQL))
+	}
+	ctx, _ = Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+// TraceQueryEnd ends the span started by TraceQueryStart.
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+	span.End()
+}
+
+// operation returns the leading keyword of a statement, e.g. "SELECT".
+func operation(sql string) string {
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		return strings.ToUpper(strings.TrimRight(fields[0], ";"))
+	}
+	return "QUERY"
+}
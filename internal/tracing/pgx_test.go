@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestQueryTracer(t *testing.T) {
+	const sql = "SELECT id, title FROM articles WHERE id = $1"
+
+	tests := []struct {
+		name      string
+		tracer    QueryTracer
+		statement string
+		wantName  string
+		wantText  bool
+	}{
+		{"keyword only", QueryTracer{}, "", "SELECT", false},
+		{"statement", QueryTracer{}, "GetArticleByID", "SELECT GetArticleByID", false},
+		{"query text", QueryTracer{QueryText: true}, "GetArticleByID", "SELECT GetArticleByID", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			previous := otel.GetTracerProvider()
+			otel.SetTracerProvider(provider)
+			t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+			ctx := context.Background()
+			if tt.statement != "" {
+				ctx = WithStatement(ctx, tt.statement)
+			}
+			ctx = tt.tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql, Args: []any{42}})
+			tt.tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != tt.wantName {
+				t.Errorf("got span name %q, want %q", span.Name(), tt.wantName)
+			}
+			var gotText bool
+			for _, attr := range span.Attributes() {
+				switch attr.Key {
+				case semconv.DBQueryTextKey:
+					gotText = attr.Value.AsString() == sql
+				case semconv.DBOperationNameKey:
+					if attr.Value.AsString() != "SELECT" {
+						t.Errorf("got db.operation.name %q, want SELECT", attr.Value.AsString())
+					}
+				}
+			}
+			if gotText != tt.wantText {
+				t.Errorf("query text recorded = %v, want %v", gotText, tt.wantText)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package tracing sets up OpenTelemetry tracing for the API: the tracer
+// provider and exporter, W3C trace context propagation, and a pgx tracer
+// that records a span for every database query.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans created by this module.
+const instrumentationName = "github.com/jalikey/zysj-backend"
+
+// Tracer returns the tracer used for the module's own spans.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup installs the global tracer provider and the W3C trace context and
+// baggage propagators, and returns a function that flushes and stops the
+// provider.
+//
+// The exporter is chosen with OTEL_TRACES_EXPORTER:
+//
+//   - otlp: OTLP over HTTP, configured by the standard OTEL_EXPORTER_OTLP_*
+//     variables (endpoint, headers, timeout, ...)
+//   - stdout: pretty-printed spans on stdout, for local testing
+//   - none: spans are not exported
+//
+// When it is unset, otlp is used if an OTLP endpoint is configured and none
+// otherwise. The service name defaults to serviceName and can be overridden
+// with OTEL_SERVICE_NAME or OTEL_RESOURCE_ATTRIBUTES; sampling follows
+// OTEL_TRACES_SAMPLER.
+func Setup(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	exporter, err := newExporter(ctx)
+	if err != nil || exporter == nil {
+		return func(context.Context) error { return nil }, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("building trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// newExporter returns the exporter selected by the environment, or nil
+// when spans should not be exported.
+func newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	name := strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER"))
+	if name == "" {
+		name = "none"
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+			name = "otlp"
+		}
+	}
+
+	switch name {
+	case "otlp":
+		return otlptracehttp.New(ctx)
+	case "stdout", "console":
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %q (use otlp, stdout or none)", name)
+	}
+}